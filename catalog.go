@@ -0,0 +1,167 @@
+package hclfuncs
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// catalogFormatVersion is the format version of the JSON document printed by
+// `terraform metadata functions -json` that FunctionCatalog follows.
+const catalogFormatVersion = "1.0"
+
+// FunctionCatalog describes every function of a function table.
+type FunctionCatalog struct {
+	// Functions is sorted by name.
+	Functions []FunctionMetadata
+}
+
+// FunctionMetadata describes the signature and origin of a function.
+type FunctionMetadata struct {
+	Name              string              `json:"-"`
+	Description       string              `json:"description,omitempty"`
+	ReturnType        cty.Type            `json:"return_type"`
+	Parameters        []ParameterMetadata `json:"parameters,omitempty"`
+	VariadicParameter *ParameterMetadata  `json:"variadic_parameter,omitempty"`
+	// Category, Pure and Upstream are only known for built-in functions.
+	Category Category `json:"category,omitempty"`
+	Pure     bool     `json:"pure"`
+	Upstream string   `json:"upstream,omitempty"`
+}
+
+// ParameterMetadata describes a function parameter.
+type ParameterMetadata struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	IsNullable  bool     `json:"is_nullable,omitempty"`
+	Type        cty.Type `json:"type"`
+}
+
+// Catalog describes the functions of the table that NewFunctions builds
+// from the same options.
+func Catalog(opts ...Option) (FunctionCatalog, error) {
+	table, err := buildTable(opts...)
+	if err != nil {
+		return FunctionCatalog{}, err
+	}
+	var c FunctionCatalog
+	for name, f := range table.functions {
+		c.Functions = append(c.Functions, describeFunction(name, table.builtins[name], f))
+	}
+	sort.Slice(c.Functions, func(i, j int) bool {
+		return c.Functions[i].Name < c.Functions[j].Name
+	})
+	return c, nil
+}
+
+// Lookup returns the metadata of the named function.
+func (c FunctionCatalog) Lookup(name string) (FunctionMetadata, bool) {
+	i := sort.Search(len(c.Functions), func(i int) bool {
+		return c.Functions[i].Name >= name
+	})
+	if i < len(c.Functions) && c.Functions[i].Name == name {
+		return c.Functions[i], true
+	}
+	return FunctionMetadata{}, false
+}
+
+// MarshalJSON encodes the catalog in the shape printed by
+// `terraform metadata functions -json`, with the category, pure and upstream
+// attributes added to each signature.
+func (c FunctionCatalog) MarshalJSON() ([]byte, error) {
+	signatures := make(map[string]FunctionMetadata, len(c.Functions))
+	for _, f := range c.Functions {
+		signatures[f.Name] = f
+	}
+	return json.Marshal(struct {
+		FormatVersion      string                      `json:"format_version"`
+		FunctionSignatures map[string]FunctionMetadata `json:"function_signatures"`
+	}{
+		FormatVersion:      catalogFormatVersion,
+		FunctionSignatures: signatures,
+	})
+}
+
+// describeFunction describes the function registered as name, which is the
+// built-in function builtin, or a user function when builtin is empty.
+func describeFunction(name, builtin string, f function.Function) FunctionMetadata {
+	m := FunctionMetadata{
+		Name:        name,
+		Description: f.Description(),
+		ReturnType:  returnType(f),
+		Pure:        IsPure(builtin),
+	}
+	if reg, ok := registry[builtin]; ok {
+		m.Category = reg.category
+		m.Upstream = reg.upstream
+		if m.Description == "" {
+			m.Description = fallbackDescriptions[builtin]
+		}
+	}
+	for _, p := range f.Params() {
+		m.Parameters = append(m.Parameters, describeParameter(p))
+	}
+	if vp := f.VarParam(); vp != nil {
+		p := describeParameter(*vp)
+		m.VariadicParameter = &p
+	}
+	return m
+}
+
+func describeParameter(p function.Parameter) ParameterMetadata {
+	return ParameterMetadata{
+		Name:        p.Name,
+		Description: p.Description,
+		IsNullable:  p.AllowNull,
+		Type:        p.Type,
+	}
+}
+
+// returnType works out the return type of f from its parameter types alone,
+// falling back to cty.DynamicPseudoType when the type depends on values.
+func returnType(f function.Function) (ty cty.Type) {
+	defer func() {
+		if r := recover(); r != nil {
+			ty = cty.DynamicPseudoType
+		}
+	}()
+	var args []cty.Value
+	for _, p := range f.Params() {
+		args = append(args, cty.UnknownVal(p.Type))
+	}
+	ty, err := f.ReturnTypeForValues(args)
+	if err != nil {
+		return cty.DynamicPseudoType
+	}
+	return ty
+}
+
+// fallbackDescriptions describes upstream functions that come without a
+// description of their own.
+var fallbackDescriptions = map[string]string{
+	"base64decode": "Takes a string containing a Base64 character sequence and returns the original string.",
+	"base64encode": "Applies Base64 encoding to a string.",
+	"basename":     "Takes a string containing a filesystem path and removes all except the last portion from it.",
+	"bcrypt":       "Computes a hash of the given string using the Blowfish cipher, returning a string in the Modular Crypt Format usually expected in the shadow password file on many Unix systems.",
+	"can":          "Evaluates the given expression and returns a boolean value indicating whether the expression produced a result without any errors.",
+	"cidrhost":     "Calculates a full host IP address for a given host number within a given IP network address prefix.",
+	"cidrnetmask":  "Converts an IPv4 address prefix given in CIDR notation into a subnet mask address.",
+	"cidrsubnet":   "Calculates a subnet address within given IP network address prefix.",
+	"cidrsubnets":  "Calculates a sequence of consecutive IP address ranges within a particular CIDR prefix.",
+	"coalesce":     "Takes any number of arguments and returns the first one that isn't null or an empty string.",
+	"convert":      "Converts a value to the given type constraint.",
+	"dirname":      "Takes a string containing a filesystem path and removes the last portion from it.",
+	"md5":          "Computes the MD5 hash of a given string and encodes it with hexadecimal digits.",
+	"pathexpand":   "Takes a filesystem path that might begin with a `~` segment, and if so it replaces that segment with the current user's home directory path.",
+	"rsadecrypt":   "Decrypts an RSA-encrypted ciphertext, returning the corresponding cleartext.",
+	"sha1":         "Computes the SHA1 hash of a given string and encodes it with hexadecimal digits.",
+	"sha256":       "Computes the SHA256 hash of a given string and encodes it with hexadecimal digits.",
+	"sha512":       "Computes the SHA512 hash of a given string and encodes it with hexadecimal digits.",
+	"try":          "Evaluates all of its argument expressions in turn and returns the result of the first one that does not produce any errors.",
+	"uuidv4":       "Generates a random version 4 UUID string.",
+	"uuidv5":       "Generates a name-based UUID, as described in RFC 4122 section 4.3.",
+	"yamldecode":   "Parses a string as a subset of YAML, and produces a representation of its value.",
+	"yamlencode":   "Encodes a given value to a string using YAML 1.2 block syntax.",
+}
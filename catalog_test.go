@@ -0,0 +1,94 @@
+package hclfuncs
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestCatalog_DescribesEveryFunction(t *testing.T) {
+	c, err := Catalog()
+	require.NoError(t, err)
+	require.Len(t, c.Functions, len(registry))
+	for _, f := range c.Functions {
+		assert.NotEmpty(t, f.Description, f.Name)
+		assert.NotEmpty(t, f.Category, f.Name)
+		assert.Equal(t, IsPure(f.Name), f.Pure, f.Name)
+	}
+}
+
+func TestCatalog_Lookup(t *testing.T) {
+	c, err := Catalog()
+	require.NoError(t, err)
+
+	fileset, ok := c.Lookup("fileset")
+	require.True(t, ok)
+	assert.Equal(t, CategoryFilesystem, fileset.Category)
+	assert.False(t, fileset.Pure)
+	assert.True(t, fileset.ReturnType.Equals(cty.Set(cty.String)))
+	require.Len(t, fileset.Parameters, 2)
+	assert.Equal(t, "pattern", fileset.Parameters[1].Name)
+
+	format, ok := c.Lookup("format")
+	require.True(t, ok)
+	require.NotNil(t, format.VariadicParameter)
+	assert.Equal(t, "args", format.VariadicParameter.Name)
+	assert.Equal(t, upstreamStdlib, format.Upstream)
+
+	env, ok := c.Lookup("env")
+	require.True(t, ok)
+	require.NotNil(t, env.VariadicParameter)
+	assert.Contains(t, env.VariadicParameter.Description, "at most one")
+
+	_, ok = c.Lookup("not_a_function")
+	assert.False(t, ok)
+}
+
+func TestCatalog_FollowsOptions(t *testing.T) {
+	c, err := Catalog(WithInclude(string(CategoryNetwork)))
+	require.NoError(t, err)
+	for _, f := range c.Functions {
+		assert.Equal(t, CategoryNetwork, f.Category)
+	}
+}
+
+func TestCatalog_JSON(t *testing.T) {
+	c, err := Catalog(WithInclude("sha256", "compliment"))
+	require.NoError(t, err)
+	b, err := json.Marshal(c)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+  "format_version": "1.0",
+  "function_signatures": {
+    "compliment": {
+      "description": "Return the compliment of list1 and all otherLists.",
+      "return_type": ["set", "dynamic"],
+      "parameters": [
+        {
+          "name": "list1",
+          "description": "the first list, will return all elements that in this list but not in any of other lists.",
+          "type": ["set", "dynamic"]
+        }
+      ],
+      "variadic_parameter": {
+        "name": "otherList",
+        "description": "other_list",
+        "type": ["set", "dynamic"]
+      },
+      "category": "collections",
+      "pure": true
+    },
+    "sha256": {
+      "description": "Computes the SHA256 hash of a given string and encodes it with hexadecimal digits.",
+      "return_type": "string",
+      "parameters": [{"name": "str", "type": "string"}],
+      "category": "crypto",
+      "pure": true,
+      "upstream": "github.com/hashicorp/go-cty-funcs"
+    }
+  }
+}`, string(b))
+}
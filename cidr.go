@@ -11,6 +11,7 @@ import (
 // CidrContainsFunc constructs a function that checks whether a given IP address
 // is within a given IP network address prefix.
 var CidrContainsFunc = function.New(&function.Spec{
+	Description: "Determines whether a given IP address or an address prefix given in CIDR notation is within a given IP network address prefix.",
 	Params: []function.Parameter{
 		{
 			Name: "containing_prefix",
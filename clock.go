@@ -0,0 +1,62 @@
+package hclfuncs
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is the time source used by time functions such as timestamp.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is a Clock that reads the current system time.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+// FixedClock returns a Clock that always returns t.
+func FixedClock(t time.Time) Clock {
+	return fixedClock(t)
+}
+
+type fixedClock time.Time
+
+func (c fixedClock) Now() time.Time {
+	return time.Time(c)
+}
+
+// StepClock returns a Clock that returns start on the first call and
+// advances by step on every following call. It is safe for concurrent use.
+func StepClock(start time.Time, step time.Duration) Clock {
+	return &stepClock{next: start, step: step}
+}
+
+type stepClock struct {
+	mu   sync.Mutex
+	next time.Time
+	step time.Duration
+}
+
+func (c *stepClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.next
+	c.next = c.next.Add(c.step)
+	return now
+}
+
+// WithClock sets the time source of the function table. timestamp, uuidv7
+// and ulid read the clock on every call, while legacy_isotime and
+// legacy_strftime use the build time read from the clock once, on the first
+// call of either. Without this option the system clock is used and the build
+// time is InitTime.
+func WithClock(clock Clock) Option {
+	return func(c *config) {
+		c.clock = clock
+	}
+}
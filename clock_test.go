@@ -0,0 +1,65 @@
+package hclfuncs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestStepClock(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	clock := StepClock(start, time.Minute)
+	assert.Equal(t, start, clock.Now())
+	assert.Equal(t, start.Add(time.Minute), clock.Now())
+	assert.Equal(t, start.Add(2*time.Minute), clock.Now())
+}
+
+func TestNewFunctions_TimestampHonorsClock(t *testing.T) {
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.FixedZone("CET", 3600))
+	funcs, err := NewFunctions(WithClock(StepClock(start, time.Hour)))
+	require.NoError(t, err)
+
+	v, err := funcs["timestamp"].Call(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-02T02:04:05Z", v.AsString())
+	v, err = funcs["timestamp"].Call(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-02T03:04:05Z", v.AsString())
+
+	// The build time is the reading taken by the first legacy call.
+	v, err = funcs["legacy_isotime"].Call(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-02T04:04:05Z", v.AsString())
+	v, err = funcs["timestamp"].Call(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "2024-01-02T05:04:05Z", v.AsString())
+}
+
+func TestNewFunctions_LegacyTimeFunctionsUseBuildTime(t *testing.T) {
+	buildTime := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	funcs, err := NewFunctions(WithClock(StepClock(buildTime, time.Hour)))
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		v, err := funcs["legacy_isotime"].Call(nil)
+		require.NoError(t, err)
+		assert.Equal(t, "2024-01-02T03:04:05Z", v.AsString())
+		v, err = funcs["legacy_isotime"].Call([]cty.Value{cty.StringVal("2006-01-02")})
+		require.NoError(t, err)
+		assert.Equal(t, "2024-01-02", v.AsString())
+		v, err = funcs["legacy_strftime"].Call([]cty.Value{cty.StringVal("%Y-%m-%d %H")})
+		require.NoError(t, err)
+		assert.Equal(t, "2024-01-02 03", v.AsString())
+	}
+}
+
+func TestNewFunctions_DefaultLegacyTimeIsInitTime(t *testing.T) {
+	funcs, err := NewFunctions()
+	require.NoError(t, err)
+	v, err := funcs["legacy_isotime"].Call(nil)
+	require.NoError(t, err)
+	assert.Equal(t, InitTime.Format(time.RFC3339), v.AsString())
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/lonegunmanb/hclfuncs/marks"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// formatValue renders v in HCL syntax, the way terraform console does.
+// Sensitive values, including those nested in collections, are printed as
+// (sensitive) and unknown values as (unknown).
+func formatValue(v cty.Value) string {
+	var b strings.Builder
+	writeValue(&b, v, 0)
+	return b.String()
+}
+
+func writeValue(b *strings.Builder, v cty.Value, indent int) {
+	if v.HasMark(marks.Sensitive) {
+		b.WriteString("(sensitive)")
+		return
+	}
+	v, _ = v.Unmark()
+	if !v.IsKnown() {
+		b.WriteString("(unknown)")
+		return
+	}
+	if v.IsNull() {
+		b.WriteString("null")
+		return
+	}
+
+	ty := v.Type()
+	switch {
+	case ty == cty.String:
+		b.WriteString(quoteString(v.AsString()))
+	case ty == cty.Number:
+		b.WriteString(v.AsBigFloat().Text('f', -1))
+	case ty == cty.Bool:
+		b.WriteString(strconv.FormatBool(v.True()))
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		if v.LengthInt() == 0 {
+			b.WriteString("[]")
+			return
+		}
+		b.WriteString("[\n")
+		for it := v.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+			writeIndent(b, indent+1)
+			writeValue(b, elem, indent+1)
+			b.WriteString(",\n")
+		}
+		writeIndent(b, indent)
+		b.WriteString("]")
+	case ty.IsMapType() || ty.IsObjectType():
+		if v.LengthInt() == 0 {
+			b.WriteString("{}")
+			return
+		}
+		b.WriteString("{\n")
+		for it := v.ElementIterator(); it.Next(); {
+			k, elem := it.Element()
+			writeIndent(b, indent+1)
+			if key := k.AsString(); hclsyntax.ValidIdentifier(key) {
+				b.WriteString(key)
+			} else {
+				b.WriteString(quoteString(key))
+			}
+			b.WriteString(" = ")
+			writeValue(b, elem, indent+1)
+			b.WriteString("\n")
+		}
+		writeIndent(b, indent)
+		b.WriteString("}")
+	default:
+		b.WriteString(v.GoString())
+	}
+}
+
+func writeIndent(b *strings.Builder, indent int) {
+	b.WriteString(strings.Repeat("  ", indent))
+}
+
+// quoteString returns s as a quoted HCL string literal, escaping template
+// sequences so that the literal evaluates back to s.
+func quoteString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i, r := range s {
+		switch r {
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '$', '%':
+			b.WriteRune(r)
+			if strings.HasPrefix(s[i+1:], "{") {
+				b.WriteRune(r)
+			}
+		default:
+			if unicode.IsPrint(r) {
+				b.WriteRune(r)
+			} else if r > 0xFFFF {
+				fmt.Fprintf(&b, `\U%08x`, r)
+			} else {
+				fmt.Fprintf(&b, `\u%04x`, r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
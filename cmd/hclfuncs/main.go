@@ -0,0 +1,183 @@
+// Command hclfuncs evaluates HCL expressions against the functions of the
+// hclfuncs package, either one expression at a time or in an interactive
+// session.
+//
+// Usage:
+//
+//	hclfuncs eval [flags] <expression>
+//	hclfuncs repl [flags]
+//
+// Flags:
+//
+//	-var-file path  load variables from a .json, .yaml, .yml or .tfvars file,
+//	                may be repeated, later files win
+//	-base-dir dir   directory that file functions resolve relative paths
+//	                against, defaults to the working directory
+//
+// Loaded variables are available to expressions as var.<name>.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/lonegunmanb/hclfuncs"
+	"github.com/lonegunmanb/hclfuncs/marks"
+	"github.com/zclconf/go-cty/cty"
+)
+
+const usage = `Usage:
+  hclfuncs eval [flags] <expression>   evaluate one expression and print its value
+  hclfuncs repl [flags]                start an interactive session
+
+Flags:
+  -var-file path   load variables from a .json, .yaml, .yml or .tfvars file,
+                   may be repeated, later files win
+  -base-dir dir    directory that file functions resolve relative paths against
+
+Loaded variables are available to expressions as var.<name>.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// run executes the command line in args and returns the process exit code.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	cmd, args := args[0], args[1:]
+	switch cmd {
+	case "eval", "repl":
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n\n%s", cmd, usage)
+		return 2
+	}
+
+	flags, err := parseFlags(cmd, args, stderr)
+	if err != nil {
+		return 2
+	}
+	vars, err := loadVarFiles(flags.varFiles)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	ev := newEvaluator(flags.baseDir, vars)
+
+	if cmd == "eval" {
+		if len(flags.args) != 1 {
+			fmt.Fprintf(stderr, "eval expects exactly one expression\n\n%s", usage)
+			return 2
+		}
+		if !ev.print(flags.args[0], stdout, stderr) {
+			return 1
+		}
+		return 0
+	}
+
+	if len(flags.args) != 0 {
+		fmt.Fprintf(stderr, "repl takes no arguments\n\n%s", usage)
+		return 2
+	}
+	if err := repl(ev, stdin, stdout, stderr); err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// evaluator evaluates expressions against the hclfuncs function table and
+// the loaded variables.
+type evaluator struct {
+	ctx *hcl.EvalContext
+}
+
+func newEvaluator(baseDir string, vars map[string]cty.Value) *evaluator {
+	return &evaluator{ctx: &hcl.EvalContext{
+		Variables: map[string]cty.Value{"var": cty.ObjectVal(vars)},
+		Functions: hclfuncs.Functions(baseDir),
+	}}
+}
+
+func (e *evaluator) eval(src string) (cty.Value, hcl.Diagnostics) {
+	expr, diags := hclsyntax.ParseExpression([]byte(src), exprFilename, hcl.InitialPos)
+	if diags.HasErrors() {
+		return cty.DynamicVal, diags
+	}
+	return expr.Value(e.ctx)
+}
+
+// print evaluates src and writes its value to out, or the diagnostics to
+// errOut. It reports whether the evaluation succeeded.
+func (e *evaluator) print(src string, out, errOut io.Writer) bool {
+	v, diags := e.eval(src)
+	if diags.HasErrors() {
+		writeDiagnostics(errOut, src, diags)
+		return false
+	}
+	fmt.Fprintln(out, formatValue(v))
+	return true
+}
+
+// names returns the names that can be completed in an expression: function
+// names and var.<name> for every loaded variable.
+func (e *evaluator) names() []string {
+	var names []string
+	for name := range e.ctx.Functions {
+		names = append(names, name)
+	}
+	for name := range e.ctx.Variables["var"].Type().AttributeTypes() {
+		names = append(names, "var."+name)
+	}
+	return names
+}
+
+const exprFilename = "<expression>"
+
+func writeDiagnostics(w io.Writer, src string, diags hcl.Diagnostics) {
+	files := map[string]*hcl.File{exprFilename: {Bytes: []byte(src)}}
+	_ = hcl.NewDiagnosticTextWriter(w, files, 0, false).WriteDiagnostics(marks.ScrubDiagnostics(diags))
+}
+
+type cliFlags struct {
+	varFiles []string
+	baseDir  string
+	args     []string
+}
+
+func parseFlags(cmd string, args []string, stderr io.Writer) (cliFlags, error) {
+	var f cliFlags
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() { fmt.Fprint(stderr, usage) }
+	fs.Var((*stringList)(&f.varFiles), "var-file", "load variables from a .json, .yaml, .yml or .tfvars file")
+	fs.StringVar(&f.baseDir, "base-dir", ".", "directory that file functions resolve relative paths against")
+	if err := fs.Parse(args); err != nil {
+		return f, err
+	}
+	f.args = fs.Args()
+	return f, nil
+}
+
+// stringList is a flag.Value collecting every occurrence of a flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
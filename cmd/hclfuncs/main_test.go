@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lonegunmanb/hclfuncs/marks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func runCmd(stdin string, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, strings.NewReader(stdin), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func TestEval(t *testing.T) {
+	code, out, _ := runCmd("", "eval", `cidrsubnets("10.0.0.0/16", 4, 4)`)
+	assert.Equal(t, 0, code)
+	assert.Equal(t, "[\n  \"10.0.0.0/20\",\n  \"10.0.16.0/20\",\n]\n", out)
+
+	code, _, errOut := runCmd("", "eval", `upper(`)
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "on <expression> line 1")
+
+	code, _, _ = runCmd("", "eval")
+	assert.Equal(t, 2, code)
+
+	code, _, _ = runCmd("", "unknown")
+	assert.Equal(t, 2, code)
+}
+
+func TestEval_VarFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.json":   `{"name": "json", "ports": [80, 443]}`,
+		"b.yaml":   "name: yaml\nzone: a\n",
+		"c.tfvars": `region = "westeurope"`,
+	}
+	var args []string
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+	for _, name := range []string{"a.json", "b.yaml", "c.tfvars"} {
+		args = append(args, "-var-file", filepath.Join(dir, name))
+	}
+
+	code, out, errOut := runCmd("", append([]string{"eval"}, append(args, `"${var.name}-${var.zone}-${var.region}-${var.ports[1]}"`)...)...)
+	require.Equal(t, 0, code, errOut)
+	assert.Equal(t, "\"yaml-a-westeurope-443\"\n", out)
+
+	code, _, errOut = runCmd("", "eval", "-var-file", filepath.Join(dir, "vars.txt"), "1")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "failed to read variable file")
+}
+
+func TestRepl_NonInteractive(t *testing.T) {
+	code, out, errOut := runCmd("upper(\"a\")\n\nsensitive(\"s\")\nexit\nlower(\"B\")\n", "repl")
+	assert.Equal(t, 0, code, errOut)
+	assert.Equal(t, "\"A\"\n(sensitive)\n", out)
+
+	code, _, errOut = runCmd("nope()\n", "repl")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, errOut, "Call to unknown function")
+}
+
+func TestFormatValue(t *testing.T) {
+	v := cty.ObjectVal(map[string]cty.Value{
+		"list":    cty.ListVal([]cty.Value{cty.StringVal("a\n${b}"), cty.StringVal("x").Mark(marks.Sensitive)}),
+		"a key":   cty.NumberFloatVal(1.5),
+		"empty":   cty.EmptyTupleVal,
+		"unknown": cty.UnknownVal(cty.Bool),
+		"null":    cty.NullVal(cty.String),
+	})
+	expected := `{
+  "a key" = 1.5
+  empty = []
+  list = [
+    "a\n$${b}",
+    (sensitive),
+  ]
+  null = null
+  unknown = (unknown)
+}`
+	assert.Equal(t, expected, formatValue(v))
+}
+
+func TestCompleter(t *testing.T) {
+	complete := completer([]string{"upper", "uuid", "uuidv4", "var.region"})
+	cases := []struct {
+		line, expected string
+		ok             bool
+	}{
+		{line: "up", expected: "upper(", ok: true},
+		{line: "uu", expected: "uuid", ok: true},
+		{line: "uuid", ok: false},
+		{line: "join(\",\", va", expected: "join(\",\", var.region", ok: true},
+		{line: "x", ok: false},
+	}
+	for _, c := range cases {
+		line, pos, ok := complete(c.line, len(c.line), '\t')
+		assert.Equal(t, c.ok, ok, c.line)
+		if c.ok {
+			assert.Equal(t, c.expected, line)
+			assert.Equal(t, len(c.expected), pos)
+		}
+	}
+}
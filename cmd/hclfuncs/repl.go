@@ -0,0 +1,191 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+const replHelp = `Enter an HCL expression to evaluate it, e.g. cidrsubnets("10.0.0.0/16", 4, 4).
+Loaded variables are available as var.<name>. Press Tab to complete function
+and variable names, Up and Down to browse the history.
+
+Commands:
+  help   show this message
+  exit   leave the session, Ctrl-D works too
+`
+
+// maxHistory is the number of lines kept in the history file.
+const maxHistory = 500
+
+// repl evaluates the expressions read from stdin line by line. When stdin is
+// a terminal, the session is interactive, with a prompt, history and tab
+// completion; otherwise results go to stdout and diagnostics to stderr, and
+// an error is returned if any expression failed.
+func repl(ev *evaluator, stdin io.Reader, stdout, stderr io.Writer) error {
+	if f, ok := stdin.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		return interactive(ev, f, stdout)
+	}
+
+	failed := false
+	scanner := bufio.NewScanner(stdin)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if line == "exit" {
+			break
+		}
+		if !ev.print(line, stdout, stderr) {
+			failed = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	if failed {
+		return errors.New("some expressions failed to evaluate")
+	}
+	return nil
+}
+
+func interactive(ev *evaluator, tty *os.File, stdout io.Writer) error {
+	fd := int(tty.Fd())
+	state, err := term.MakeRaw(fd)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = term.Restore(fd, state) }()
+
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{tty, stdout}, "> ")
+	if width, height, err := term.GetSize(fd); err == nil {
+		_ = t.SetSize(width, height)
+	}
+	t.AutoCompleteCallback = completer(ev.names())
+
+	history := historyFile()
+	for _, line := range readHistory(history) {
+		t.History.Add(line)
+	}
+
+	fmt.Fprintln(t, `Type "help" for help, "exit" or Ctrl-D to leave.`)
+	for {
+		line, err := t.ReadLine()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		switch line {
+		case "":
+			continue
+		case "exit":
+			return nil
+		case "help":
+			fmt.Fprint(t, replHelp)
+			continue
+		}
+		appendHistory(history, line)
+		ev.print(line, t, t)
+	}
+}
+
+// completer returns a terminal auto-complete callback that completes the
+// name before the cursor to the longest prefix shared by all matching names,
+// opening the argument list when a single function matches.
+func completer(names []string) func(line string, pos int, key rune) (string, int, bool) {
+	names = append([]string(nil), names...)
+	sort.Strings(names)
+	return func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+		start := pos
+		for start > 0 && isNameByte(line[start-1]) {
+			start--
+		}
+		prefix := line[start:pos]
+		if prefix == "" {
+			return "", 0, false
+		}
+		var matches []string
+		for _, name := range names {
+			if strings.HasPrefix(name, prefix) {
+				matches = append(matches, name)
+			}
+		}
+		if len(matches) == 0 {
+			return "", 0, false
+		}
+		completion := matches[0]
+		for _, m := range matches[1:] {
+			for !strings.HasPrefix(m, completion) {
+				completion = completion[:len(completion)-1]
+			}
+		}
+		if len(matches) == 1 && !strings.HasPrefix(completion, "var.") {
+			completion += "("
+		}
+		if completion == prefix {
+			return "", 0, false
+		}
+		return line[:start] + completion + line[pos:], start + len(completion), true
+	}
+}
+
+func isNameByte(c byte) bool {
+	return c == '_' || c == '.' || c == ':' ||
+		'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// historyFile returns the path of the file the REPL history is kept in, or
+// "" if there is no home directory to keep it in.
+func historyFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".hclfuncs_history")
+}
+
+func readHistory(path string) []string {
+	if path == "" {
+		return nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if len(lines) > maxHistory {
+		lines = lines[len(lines)-maxHistory:]
+	}
+	return lines
+}
+
+// appendHistory adds line to the history file. Failing to keep the history
+// is not worth interrupting the session for, so errors are ignored.
+func appendHistory(path, line string) {
+	if path == "" {
+		return
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer func() { _ = f.Close() }()
+	_, _ = fmt.Fprintln(f, line)
+}
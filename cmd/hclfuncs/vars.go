@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	hcljson "github.com/hashicorp/hcl/v2/json"
+	ctyyaml "github.com/zclconf/go-cty-yaml"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// loadVarFiles reads the variables defined in the given files. When several
+// files define the same variable, the last one wins.
+func loadVarFiles(paths []string) (map[string]cty.Value, error) {
+	vars := make(map[string]cty.Value)
+	for _, path := range paths {
+		fileVars, err := loadVarFile(path)
+		if err != nil {
+			return nil, err
+		}
+		for name, v := range fileVars {
+			vars[name] = v
+		}
+	}
+	return vars, nil
+}
+
+func loadVarFile(path string) (map[string]cty.Value, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read variable file %s: %w", path, err)
+	}
+	switch {
+	case strings.HasSuffix(path, ".json"):
+		f, diags := hcljson.Parse(src, path)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		return attributeValues(f.Body)
+	case strings.HasSuffix(path, ".yaml"), strings.HasSuffix(path, ".yml"):
+		return yamlValues(path, src)
+	case strings.HasSuffix(path, ".tfvars"):
+		f, diags := hclsyntax.ParseConfig(src, path, hcl.InitialPos)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		return attributeValues(f.Body)
+	}
+	return nil, fmt.Errorf("unsupported variable file %s: must end in .json, .yaml, .yml or .tfvars", path)
+}
+
+// attributeValues evaluates the top-level attributes of body. Like tfvars
+// files in Terraform, they can't refer to variables or call functions.
+func attributeValues(body hcl.Body) (map[string]cty.Value, error) {
+	attrs, diags := body.JustAttributes()
+	if diags.HasErrors() {
+		return nil, diags
+	}
+	vars := make(map[string]cty.Value, len(attrs))
+	for name, attr := range attrs {
+		v, diags := attr.Expr.Value(nil)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+		vars[name] = v
+	}
+	return vars, nil
+}
+
+func yamlValues(path string, src []byte) (map[string]cty.Value, error) {
+	ty, err := ctyyaml.ImpliedType(src)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse variable file %s: %w", path, err)
+	}
+	if !ty.IsObjectType() && !ty.IsMapType() {
+		return nil, fmt.Errorf("variable file %s must contain a mapping of variable names to values", path)
+	}
+	v, err := ctyyaml.Unmarshal(src, ty)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse variable file %s: %w", path, err)
+	}
+	if v.IsNull() {
+		return nil, nil
+	}
+	return v.AsValueMap(), nil
+}
@@ -14,6 +14,7 @@ import (
 
 // IndexFunc constructs a function that finds the element index for a given value in a list.
 var IndexFunc = function.New(&function.Spec{
+	Description: "Finds the element index for a given value in a list.",
 	Params: []function.Parameter{
 		{
 			Name: "list",
@@ -84,6 +85,7 @@ var ComplimentFunction = function.New(&function.Spec{
 // indexes of values in another list.
 // Copy from https://github.com/opentofu/opentofu/blob/v1.7.1/internal/lang/funcs/collection.go
 var MatchkeysFunc = function.New(&function.Spec{
+	Description: "Constructs a new list by taking a subset of elements from one list whose indexes match the corresponding indexes of values in another list.",
 	Params: []function.Parameter{
 		{
 			Name: "values",
@@ -169,6 +171,7 @@ var MatchkeysFunc = function.New(&function.Spec{
 // numbers provided in a list
 // Copy from https://github.com/opentofu/opentofu/blob/v1.7.1/internal/lang/funcs/collection.go
 var SumFunc = function.New(&function.Spec{
+	Description: "Takes a list or set of numbers and returns the sum of those numbers.",
 	Params: []function.Parameter{
 		{
 			Name: "list",
@@ -240,6 +243,7 @@ var SumFunc = function.New(&function.Spec{
 // swaps the keys and values to produce a new map of lists of strings.
 // Copy from https://github.com/opentofu/opentofu/blob/v1.7.1/internal/lang/funcs/collection.go
 var TransposeFunc = function.New(&function.Spec{
+	Description: "Takes a map of lists of strings and swaps the keys and values to produce a new map of lists of strings.",
 	Params: []function.Parameter{
 		{
 			Name: "values",
@@ -293,6 +297,7 @@ var TransposeFunc = function.New(&function.Spec{
 })
 
 var LengthFunc = function.New(&function.Spec{
+	Description: "Determines the length of a given list, map, or string.",
 	Params: []function.Parameter{
 		{
 			Name:             "value",
@@ -337,6 +342,7 @@ var LengthFunc = function.New(&function.Spec{
 })
 
 var YAML2JsonFunc = function.New(&function.Spec{
+	Description: "Converts a YAML document to its JSON representation.",
 	Params: []function.Parameter{
 		{
 			Name: "src",
@@ -364,6 +370,7 @@ var YAML2JsonFunc = function.New(&function.Spec{
 // AllTrueFunc constructs a function that returns true if all elements of the
 // list are true. If the list is empty, return true.
 var AllTrueFunc = function.New(&function.Spec{
+	Description: "Returns `true` if all elements in a given collection are `true` or `\"true\"`. It also returns `true` if the collection is empty.",
 	Params: []function.Parameter{
 		{
 			Name: "list",
@@ -393,6 +400,7 @@ var AllTrueFunc = function.New(&function.Spec{
 // AnyTrueFunc constructs a function that returns true if any element of the
 // list is true. If the list is empty, return false.
 var AnyTrueFunc = function.New(&function.Spec{
+	Description: "Returns `true` if any element in a given collection is `true` or `\"true\"`. It also returns `false` if the collection is empty.",
 	Params: []function.Parameter{
 		{
 			Name: "list",
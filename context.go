@@ -0,0 +1,126 @@
+package hclfuncs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// WithContext binds ctx to the function table. I/O-bound functions (those
+// reading files, templatefile, vault and consul_key) stop waiting and
+// return an error once ctx is canceled or its deadline passes. To give each
+// request its own context, build a table per request with NewFunctions.
+func WithContext(ctx context.Context) Option {
+	return func(c *config) {
+		c.io.ctx = ctx
+	}
+}
+
+// WithIOTimeout caps how long each file system or secret backend operation
+// of an I/O-bound function may take. Zero, the default, means no limit.
+func WithIOTimeout(timeout time.Duration) Option {
+	return func(c *config) {
+		c.io.timeout = timeout
+	}
+}
+
+// ioContext supplies the context that I/O-bound functions run under.
+// abandoned counts the operations of the table that ioRun stopped waiting
+// for and that haven't returned yet. It is nil for the zero ioContext, whose
+// context is never done.
+type ioContext struct {
+	ctx       context.Context
+	timeout   time.Duration
+	abandoned *atomic.Int64
+}
+
+func (c ioContext) start() (context.Context, context.CancelFunc) {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if c.timeout > 0 {
+		return context.WithTimeout(ctx, c.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+// maxAbandonedIO bounds how many operations of a table ioRun stopped
+// waiting for may still be running in the background. Once it is reached,
+// new operations of that table fail right away instead of piling up behind
+// an unresponsive file system or secret backend.
+const maxAbandonedIO = 64
+
+// ioRun runs the operation fn, described by what, under the context of c. If
+// the context is done first, ioRun returns an error right away. fn gets the
+// context and should return soon after it is done, but blocking calls such
+// as opening a file on an unresponsive network file system can't be
+// interrupted, so fn keeps running in the background and its result is
+// discarded. At most maxAbandonedIO such operations are left running per
+// table.
+func ioRun[T any](c ioContext, what string, fn func(ctx context.Context) (T, error)) (T, error) {
+	ctx, cancel := c.start()
+	defer cancel()
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, contextError(what, err)
+	}
+	if c.abandoned != nil && c.abandoned.Load() >= maxAbandonedIO {
+		return zero, fmt.Errorf("%s was not started: %d earlier operations are still running after timing out or being canceled", what, maxAbandonedIO)
+	}
+
+	type result struct {
+		val T
+		err error
+	}
+	const (
+		running int32 = iota
+		finished
+		abandoned
+	)
+	var state atomic.Int32
+	done := make(chan result, 1)
+	go func() {
+		val, err := fn(ctx)
+		done <- result{val: val, err: err}
+		if !state.CompareAndSwap(running, finished) {
+			c.abandoned.Add(-1)
+		}
+	}()
+	select {
+	case r := <-done:
+		if r.err != nil && errors.Is(r.err, ctx.Err()) {
+			return zero, contextError(what, r.err)
+		}
+		return r.val, r.err
+	case <-ctx.Done():
+		if c.abandoned != nil && state.CompareAndSwap(running, abandoned) {
+			c.abandoned.Add(1)
+		}
+		return zero, contextError(what, ctx.Err())
+	}
+}
+
+// contextReader stops reading from r once ctx is done, so that a slow read
+// doesn't outlive the call that started it by more than one Read.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
+func contextError(what string, err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%s timed out: %w", what, err)
+	}
+	return fmt.Errorf("%s was canceled: %w", what, err)
+}
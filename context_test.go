@@ -0,0 +1,102 @@
+package hclfuncs
+
+import (
+	"context"
+	"io/fs"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// blockingBackend ignores its context and never answers until released.
+type blockingBackend struct {
+	release chan struct{}
+}
+
+func (b blockingBackend) Vault(_ context.Context, _, _ string) (string, error) {
+	<-b.release
+	return "", nil
+}
+
+func (b blockingBackend) Consul(_ context.Context, _ string) (string, error) {
+	<-b.release
+	return "", nil
+}
+
+// blockingFS never answers until released.
+type blockingFS struct {
+	release chan struct{}
+}
+
+func (f blockingFS) Open(string) (fs.File, error) {
+	<-f.release
+	return nil, fs.ErrNotExist
+}
+
+func TestWithContext_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, diag := evalFS(t, `file("app.hcl")`, nil, WithFS(testFS), WithBaseDir("conf"), WithContext(ctx))
+	assert.ErrorContains(t, diag, "reading conf/app.hcl was canceled: context canceled")
+
+	_, diag = evalFS(t, `vault("secret/data/app", "password")`, nil, WithSecretBackend(MemorySecretBackend{}), WithContext(ctx))
+	assert.ErrorContains(t, diag, "reading vault secret secret/data/app was canceled")
+
+	// Pure functions don't care.
+	v, diag := evalFS(t, `upper("a")`, nil, WithContext(ctx))
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, "A", v.AsString())
+}
+
+func TestWithIOTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	cases := map[string][]Option{
+		`consul_key("app/endpoint")`:   {WithSecretBackend(blockingBackend{release: release})},
+		`file("app.hcl")`:              {WithFS(blockingFS{release: release})},
+		`fileset(".", "*.hcl")`:        {WithFS(blockingFS{release: release})},
+		`templatefile("app.tmpl", {})`: {WithFS(blockingFS{release: release})},
+	}
+	for code, opts := range cases {
+		t.Run(code, func(t *testing.T) {
+			start := time.Now()
+			_, diag := evalFS(t, code, nil, append(opts, WithIOTimeout(20*time.Millisecond))...)
+			assert.Less(t, time.Since(start), time.Second)
+			assert.ErrorContains(t, diag, "timed out: context deadline exceeded")
+		})
+	}
+
+	v, diag := evalFS(t, `file("/README.md")`, nil, WithFS(testFS), WithIOTimeout(time.Second))
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, "root", v.AsString())
+}
+
+func TestIORun_AbandonedLimit(t *testing.T) {
+	release := make(chan struct{})
+	opts := []Option{WithSecretBackend(blockingBackend{release: release}), WithIOTimeout(time.Millisecond)}
+	stuck, err := NewFunctions(opts...)
+	require.NoError(t, err)
+	other, err := NewFunctions(opts...)
+	require.NoError(t, err)
+	call := func(funcs map[string]function.Function) error {
+		_, err := funcs["consul_key"].Call([]cty.Value{cty.StringVal("k")})
+		return err
+	}
+
+	for i := 0; i < maxAbandonedIO; i++ {
+		require.ErrorContains(t, call(stuck), "reading consul key k timed out")
+	}
+	assert.ErrorContains(t, call(stuck), "reading consul key k was not started")
+	// The operations left running by the first table don't count against
+	// the second one.
+	assert.ErrorContains(t, call(other), "reading consul key k timed out")
+
+	close(release)
+	require.Eventually(t, func() bool { return call(stuck) == nil }, time.Second, time.Millisecond)
+}
@@ -1,6 +1,17 @@
 package hclfuncs
 
 import (
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"io/fs"
+
 	"github.com/hashicorp/go-uuid"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/function"
@@ -8,6 +19,7 @@ import (
 
 // Copy from https://github.com/opentofu/opentofu/blob/v1.7.1/internal/lang/funcs/crypto.go
 var UUIDFunc = function.New(&function.Spec{
+	Description:  "Generates a unique identifier string.",
 	Params:       []function.Parameter{},
 	Type:         function.StaticReturnType(cty.String),
 	RefineResult: refineNotNull,
@@ -19,3 +31,190 @@ var UUIDFunc = function.New(&function.Spec{
 		return cty.StringVal(result), nil
 	},
 })
+
+// Base64Sha256Func constructs a function that computes the SHA256 hash of a
+// given string and encodes it with Base64.
+var Base64Sha256Func = makeStringHashFunction(sha256.New, base64.StdEncoding.EncodeToString, "Computes the SHA256 hash of a given string and encodes it with Base64.")
+
+// Base64Sha512Func constructs a function that computes the SHA512 hash of a
+// given string and encodes it with Base64.
+var Base64Sha512Func = makeStringHashFunction(sha512.New, base64.StdEncoding.EncodeToString, "Computes the SHA512 hash of a given string and encodes it with Base64.")
+
+func makeStringHashFunction(hf func() hash.Hash, enc func([]byte) string, description string) function.Function {
+	return function.New(&function.Spec{
+		Description: description,
+		Params: []function.Parameter{
+			{
+				Name: "str",
+				Type: cty.String,
+			},
+		},
+		Type:         function.StaticReturnType(cty.String),
+		RefineResult: refineNotNull,
+		Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+			s := args[0].AsString()
+			h := hf()
+			h.Write([]byte(s))
+			rv := enc(h.Sum(nil))
+			return cty.StringVal(rv), nil
+		},
+	})
+}
+
+// MakeFileBase64Sha256Func constructs a function that is like
+// Base64Sha256Func but reads the contents of a file rather than hashing a
+// given literal string. The file is read from fsys, or from the host OS when
+// fsys is nil.
+func MakeFileBase64Sha256Func(fsys fs.FS, baseDir string) function.Function {
+	return fileBase64Sha256Func(fileSystem{fsys: fsys, baseDir: baseDir})
+}
+
+// MakeFileBase64Sha512Func constructs a function that is like
+// Base64Sha512Func but reads the contents of a file rather than hashing a
+// given literal string. The file is read from fsys, or from the host OS when
+// fsys is nil.
+func MakeFileBase64Sha512Func(fsys fs.FS, baseDir string) function.Function {
+	return fileBase64Sha512Func(fileSystem{fsys: fsys, baseDir: baseDir})
+}
+
+// MakeFileMd5Func constructs a function that is like md5 but reads the
+// contents of a file rather than hashing a given literal string. The file is
+// read from fsys, or from the host OS when fsys is nil.
+func MakeFileMd5Func(fsys fs.FS, baseDir string) function.Function {
+	return fileMd5Func(fileSystem{fsys: fsys, baseDir: baseDir})
+}
+
+// MakeFileSha1Func constructs a function that is like sha1 but reads the
+// contents of a file rather than hashing a given literal string. The file is
+// read from fsys, or from the host OS when fsys is nil.
+func MakeFileSha1Func(fsys fs.FS, baseDir string) function.Function {
+	return fileSha1Func(fileSystem{fsys: fsys, baseDir: baseDir})
+}
+
+// MakeFileSha256Func constructs a function that is like sha256 but reads the
+// contents of a file rather than hashing a given literal string. The file is
+// read from fsys, or from the host OS when fsys is nil.
+func MakeFileSha256Func(fsys fs.FS, baseDir string) function.Function {
+	return fileSha256Func(fileSystem{fsys: fsys, baseDir: baseDir})
+}
+
+// MakeFileSha512Func constructs a function that is like sha512 but reads the
+// contents of a file rather than hashing a given literal string. The file is
+// read from fsys, or from the host OS when fsys is nil.
+func MakeFileSha512Func(fsys fs.FS, baseDir string) function.Function {
+	return fileSha512Func(fileSystem{fsys: fsys, baseDir: baseDir})
+}
+
+func fileBase64Sha256Func(files fileSystem) function.Function {
+	return makeFileHashFunction(files, sha256.New, base64.StdEncoding.EncodeToString, "Computes the SHA256 hash of the contents of the given file and encodes it with Base64.")
+}
+
+func fileBase64Sha512Func(files fileSystem) function.Function {
+	return makeFileHashFunction(files, sha512.New, base64.StdEncoding.EncodeToString, "Computes the SHA512 hash of the contents of the given file and encodes it with Base64.")
+}
+
+func fileMd5Func(files fileSystem) function.Function {
+	return makeFileHashFunction(files, md5.New, hex.EncodeToString, "Computes the MD5 hash of the contents of the given file and encodes it with hexadecimal digits.")
+}
+
+func fileSha1Func(files fileSystem) function.Function {
+	return makeFileHashFunction(files, sha1.New, hex.EncodeToString, "Computes the SHA1 hash of the contents of the given file and encodes it with hexadecimal digits.")
+}
+
+func fileSha256Func(files fileSystem) function.Function {
+	return makeFileHashFunction(files, sha256.New, hex.EncodeToString, "Computes the SHA256 hash of the contents of the given file and encodes it with hexadecimal digits.")
+}
+
+func fileSha512Func(files fileSystem) function.Function {
+	return makeFileHashFunction(files, sha512.New, hex.EncodeToString, "Computes the SHA512 hash of the contents of the given file and encodes it with hexadecimal digits.")
+}
+
+func makeFileHashFunction(files fileSystem, hf func() hash.Hash, enc func([]byte) string, description string) function.Function {
+	return function.New(&function.Spec{
+		Description: description,
+		Params: []function.Parameter{
+			{
+				Name: "path",
+				Type: cty.String,
+			},
+		},
+		Type:         function.StaticReturnType(cty.String),
+		RefineResult: refineNotNull,
+		Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+			src, err := files.readFile(args[0].AsString())
+			if err != nil {
+				return cty.UnknownVal(cty.String), err
+			}
+			h := hf()
+			h.Write(src)
+			rv := enc(h.Sum(nil))
+			return cty.StringVal(rv), nil
+		},
+	})
+}
+
+// HmacSha1Func, HmacSha256Func and HmacSha512Func construct functions that
+// compute the HMAC of a message with a key, using SHA1, SHA256 or SHA512,
+// and encode it with hexadecimal digits.
+var (
+	HmacSha1Func   = makeHmacFunction(sha1.New, hex.EncodeToString, "Computes the HMAC-SHA1 of a message with the given key and encodes it with hexadecimal digits.")
+	HmacSha256Func = makeHmacFunction(sha256.New, hex.EncodeToString, "Computes the HMAC-SHA256 of a message with the given key and encodes it with hexadecimal digits.")
+	HmacSha512Func = makeHmacFunction(sha512.New, hex.EncodeToString, "Computes the HMAC-SHA512 of a message with the given key and encodes it with hexadecimal digits.")
+)
+
+// Base64HmacSha1Func, Base64HmacSha256Func and Base64HmacSha512Func are like
+// HmacSha1Func, HmacSha256Func and HmacSha512Func but encode the HMAC with
+// Base64.
+var (
+	Base64HmacSha1Func   = makeHmacFunction(sha1.New, base64.StdEncoding.EncodeToString, "Computes the HMAC-SHA1 of a message with the given key and encodes it with Base64.")
+	Base64HmacSha256Func = makeHmacFunction(sha256.New, base64.StdEncoding.EncodeToString, "Computes the HMAC-SHA256 of a message with the given key and encodes it with Base64.")
+	Base64HmacSha512Func = makeHmacFunction(sha512.New, base64.StdEncoding.EncodeToString, "Computes the HMAC-SHA512 of a message with the given key and encodes it with Base64.")
+)
+
+func makeHmacFunction(hf func() hash.Hash, enc func([]byte) string, description string) function.Function {
+	return function.New(&function.Spec{
+		Description: description,
+		Params: []function.Parameter{
+			{
+				Name:        "key",
+				Description: "The secret key",
+				Type:        cty.String,
+			},
+			{
+				Name:        "message",
+				Description: "The message to authenticate",
+				Type:        cty.String,
+			},
+		},
+		Type:         function.StaticReturnType(cty.String),
+		RefineResult: refineNotNull,
+		Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+			h := hmac.New(hf, []byte(args[0].AsString()))
+			h.Write([]byte(args[1].AsString()))
+			return cty.StringVal(enc(h.Sum(nil))), nil
+		},
+	})
+}
+
+// SecureCompareFunc constructs a function that tells whether two strings are
+// equal, taking a time that doesn't depend on their content, so that it can
+// check secrets such as signatures without leaking them through timing.
+var SecureCompareFunc = function.New(&function.Spec{
+	Description: "Returns `true` if the two given strings are equal, comparing them in constant time.",
+	Params: []function.Parameter{
+		{
+			Name: "a",
+			Type: cty.String,
+		},
+		{
+			Name: "b",
+			Type: cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(cty.Bool),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		a, b := []byte(args[0].AsString()), []byte(args[1].AsString())
+		return cty.BoolVal(subtle.ConstantTimeCompare(a, b) == 1), nil
+	},
+})
@@ -0,0 +1,49 @@
+package hclfuncs
+
+import (
+	"testing"
+
+	"github.com/lonegunmanb/hclfuncs/marks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestHmacFunctions(t *testing.T) {
+	cases := map[string]string{
+		`hmacsha1("key", "message")`:         "2088df74d5f2146b48146caf4965377e9d0be3a4",
+		`hmacsha256("key", "message")`:       "6e9ef29b75fffc5b7abae527d58fdadb2fe42e7219011976917343065f58ed4a",
+		`hmacsha512("key", "message")`:       "e477384d7ca229dd1426e64b63ebf2d36ebd6d7e669a6735424e72ea6c01d3f8b56eb39c36d8232f5427999b8d1a3f9cd1128fc69f4d75b434216810fa367e98",
+		`base64hmacsha1("key", "message")`:   "IIjfdNXyFGtIFGyvSWU3fp0L46Q=",
+		`base64hmacsha256("key", "message")`: "bp7ym3X//Ft6uuUn1Y/a2y/kLnIZARl2kXNDBl9Y7Uo=",
+		`base64hmacsha512("key", "message")`: "5Hc4TXyiKd0UJuZLY+vy0269bX5mmmc1Qk5y6mwB0/i1brOcNtgjL1QnmZuNGj+c0RKPxp9NdbQ0IWgQ+jZ+mA==",
+	}
+	for code, expected := range cases {
+		t.Run(code, func(t *testing.T) {
+			v, diag := evalFS(t, code, nil)
+			require.False(t, diag.HasErrors(), diag.Error())
+			assert.Equal(t, expected, v.AsString())
+		})
+	}
+
+	v, err := HmacSha256Func.Call([]cty.Value{cty.StringVal("key").Mark(marks.Sensitive), cty.StringVal("message")})
+	require.NoError(t, err)
+	assert.True(t, v.HasMark(marks.Sensitive))
+}
+
+func TestSecureCompare(t *testing.T) {
+	cases := map[string]bool{
+		`securecompare("abc", "abc")`: true,
+		`securecompare("abc", "abd")`: false,
+		`securecompare("abc", "ab")`:  false,
+		`securecompare("", "")`:       true,
+		`securecompare(hmacsha256("key", "message"), "6e9ef29b75fffc5b7abae527d58fdadb2fe42e7219011976917343065f58ed4a")`: true,
+	}
+	for code, expected := range cases {
+		t.Run(code, func(t *testing.T) {
+			v, diag := evalFS(t, code, nil)
+			require.False(t, diag.HasErrors(), diag.Error())
+			assert.Equal(t, expected, v.True())
+		})
+	}
+}
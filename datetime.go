@@ -11,6 +11,7 @@ import (
 
 // TimeCmpFunc is a function that compares two timestamps.
 var TimeCmpFunc = function.New(&function.Spec{
+	Description: "Compares two timestamps and returns a number that represents the ordering of the instants those timestamps represent.",
 	Params: []function.Parameter{
 		{
 			Name: "timestamp_a",
@@ -106,49 +107,92 @@ func parseTimestamp(ts string) (time.Time, error) {
 
 // LegacyIsotimeFunc constructs a function that returns a string representation
 // of the current date and time using golang's datetime formatting.
-var LegacyIsotimeFunc = function.New(&function.Spec{
-	Params: []function.Parameter{},
-	VarParam: &function.Parameter{
-		Name: "format",
-		Type: cty.String,
-	},
-	Type: function.StaticReturnType(cty.String),
-	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
-		if len(args) > 1 {
-			return cty.StringVal(""), fmt.Errorf("too many values, 1 needed: %v", args)
-		} else if len(args) == 0 {
-			return cty.StringVal(InitTime.Format(time.RFC3339)), nil
-		}
-		format := args[0].AsString()
-		return cty.StringVal(InitTime.Format(format)), nil
-	},
-})
+var LegacyIsotimeFunc = makeLegacyIsotimeFunc(func() time.Time { return InitTime })
+
+// MakeLegacyIsotimeFunc constructs a legacy_isotime function that formats the
+// given build time instead of InitTime.
+func MakeLegacyIsotimeFunc(buildTime time.Time) function.Function {
+	return makeLegacyIsotimeFunc(func() time.Time { return buildTime })
+}
+
+func makeLegacyIsotimeFunc(buildTime func() time.Time) function.Function {
+	return function.New(&function.Spec{
+		Description: "Returns the build time formatted with Go's datetime layout, or as an RFC 3339 timestamp when no format is given.",
+		Params:      []function.Parameter{},
+		VarParam: &function.Parameter{
+			Name: "format",
+			Type: cty.String,
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			if len(args) > 1 {
+				return cty.StringVal(""), fmt.Errorf("too many values, 1 needed: %v", args)
+			} else if len(args) == 0 {
+				return cty.StringVal(buildTime().Format(time.RFC3339)), nil
+			}
+			format := args[0].AsString()
+			return cty.StringVal(buildTime().Format(format)), nil
+		},
+	})
+}
 
 // LegacyStrftimeFunc constructs a function that returns a string representation
 // of the current date and time using golang's strftime datetime formatting.
-var LegacyStrftimeFunc = function.New(&function.Spec{
-	Params: []function.Parameter{},
-	VarParam: &function.Parameter{
-		Name: "format",
-		Type: cty.String,
-	},
-	Type: function.StaticReturnType(cty.String),
-	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
-		if len(args) > 1 {
-			return cty.StringVal(""), fmt.Errorf("too many values, 1 needed: %v", args)
-		} else if len(args) == 0 {
-			return cty.StringVal(InitTime.Format(time.RFC3339)), nil
-		}
-		format := args[0].AsString()
-		return cty.StringVal(strftime.Format(format, InitTime)), nil
-	},
-})
+var LegacyStrftimeFunc = makeLegacyStrftimeFunc(func() time.Time { return InitTime })
+
+// MakeLegacyStrftimeFunc constructs a legacy_strftime function that formats
+// the given build time instead of InitTime.
+func MakeLegacyStrftimeFunc(buildTime time.Time) function.Function {
+	return makeLegacyStrftimeFunc(func() time.Time { return buildTime })
+}
+
+func makeLegacyStrftimeFunc(buildTime func() time.Time) function.Function {
+	return function.New(&function.Spec{
+		Description: "Returns the build time formatted with strftime directives, or as an RFC 3339 timestamp when no format is given.",
+		Params:      []function.Parameter{},
+		VarParam: &function.Parameter{
+			Name: "format",
+			Type: cty.String,
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			if len(args) > 1 {
+				return cty.StringVal(""), fmt.Errorf("too many values, 1 needed: %v", args)
+			} else if len(args) == 0 {
+				return cty.StringVal(buildTime().Format(time.RFC3339)), nil
+			}
+			format := args[0].AsString()
+			return cty.StringVal(strftime.Format(format, buildTime())), nil
+		},
+	})
+}
+
+func legacyIsotimeFactory(c *config) function.Function {
+	if c.buildTime == nil {
+		return LegacyIsotimeFunc
+	}
+	return makeLegacyIsotimeFunc(c.buildTime)
+}
+
+func legacyStrftimeFactory(c *config) function.Function {
+	if c.buildTime == nil {
+		return LegacyStrftimeFunc
+	}
+	return makeLegacyStrftimeFunc(c.buildTime)
+}
 
 // TimestampFunc constructs a function that returns a string representation of the current date and time.
-var TimestampFunc = function.New(&function.Spec{
-	Params: []function.Parameter{},
-	Type:   function.StaticReturnType(cty.String),
-	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
-		return cty.StringVal(time.Now().UTC().Format(time.RFC3339)), nil
-	},
-})
+var TimestampFunc = MakeTimestampFunc(SystemClock)
+
+// MakeTimestampFunc constructs a timestamp function that reads the current
+// time from the given clock.
+func MakeTimestampFunc(clock Clock) function.Function {
+	return function.New(&function.Spec{
+		Description: "Returns a UTC timestamp string in RFC 3339 format.",
+		Params:      []function.Parameter{},
+		Type:        function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			return cty.StringVal(clock.Now().UTC().Format(time.RFC3339)), nil
+		},
+	})
+}
@@ -0,0 +1,354 @@
+package hclfuncs
+
+import (
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// ParseDotenv parses the content of a .env file:
+//
+//	# comments take whole lines, or follow unquoted values after a space
+//	export NAME=value
+//	SINGLE='taken $literally'
+//	DOUBLE="escapes like \n and \" are supported"
+//	MULTILINE="values in quotes
+//	can span lines"
+//	URL=https://${HOST:-localhost}:$PORT
+//
+// ${NAME}, $NAME and ${NAME:-default} in unquoted and double quoted values
+// are replaced by variables defined earlier in the same content, so that
+// the result only depends on the content; undefined variables expand to an
+// empty string. When a name is defined more than once, the last value wins.
+func ParseDotenv(src []byte) (map[string]string, error) {
+	p := &dotenvParser{src: strings.ReplaceAll(string(src), "\r\n", "\n"), line: 1}
+	vars := make(map[string]string)
+	for {
+		p.skipBlankLines()
+		if p.eof() {
+			return vars, nil
+		}
+		line := p.line
+		key, val, err := p.parseAssignment(vars)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		vars[key] = val
+	}
+}
+
+// MergeGoroutineLocalEnv adds vars to the GoroutineLocalEnv overrides of the
+// calling goroutine, replacing overrides with the same names:
+//
+//	vars, err := hclfuncs.ParseDotenv(src)
+//	if err != nil {
+//		return err
+//	}
+//	hclfuncs.MergeGoroutineLocalEnv(vars)
+func MergeGoroutineLocalEnv(vars map[string]string) {
+	merged := make(map[string]string, len(vars))
+	for k, v := range GoroutineLocalEnv.Get() {
+		merged[k] = v
+	}
+	for k, v := range vars {
+		merged[k] = v
+	}
+	GoroutineLocalEnv.Set(merged)
+}
+
+type dotenvParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+func (p *dotenvParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *dotenvParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *dotenvParser) next() byte {
+	c := p.src[p.pos]
+	p.pos++
+	if c == '\n' {
+		p.line++
+	}
+	return c
+}
+
+func (p *dotenvParser) skipSpaces() {
+	for c := p.peek(); c == ' ' || c == '\t'; c = p.peek() {
+		p.next()
+	}
+}
+
+// skipBlankLines skips empty lines and comment lines.
+func (p *dotenvParser) skipBlankLines() {
+	for !p.eof() {
+		p.skipSpaces()
+		switch p.peek() {
+		case '\n':
+			p.next()
+		case '#':
+			p.skipLine()
+		default:
+			return
+		}
+	}
+}
+
+func (p *dotenvParser) skipLine() {
+	for !p.eof() && p.next() != '\n' {
+	}
+}
+
+// endLine checks that only spaces or a comment are left on the line.
+func (p *dotenvParser) endLine() error {
+	p.skipSpaces()
+	switch p.peek() {
+	case 0, '\n':
+	case '#':
+	default:
+		return fmt.Errorf("unexpected character %q after value", p.peek())
+	}
+	p.skipLine()
+	return nil
+}
+
+func (p *dotenvParser) parseAssignment(vars map[string]string) (string, string, error) {
+	key := p.parseName()
+	if key == "export" && (p.peek() == ' ' || p.peek() == '\t') {
+		p.skipSpaces()
+		key = p.parseName()
+	}
+	if key == "" {
+		return "", "", fmt.Errorf("expected a variable name, found %q", p.peek())
+	}
+	p.skipSpaces()
+	if p.peek() != '=' {
+		return "", "", fmt.Errorf("expected = after %s", key)
+	}
+	p.next()
+	valueStart := p.pos
+	p.skipSpaces()
+
+	var val string
+	var err error
+	switch p.peek() {
+	case '\'':
+		val, err = p.parseSingleQuoted()
+	case '"':
+		val, err = p.parseDoubleQuoted(vars)
+	default:
+		return key, p.parseUnquoted(vars, p.pos > valueStart), nil
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return key, val, p.endLine()
+}
+
+func (p *dotenvParser) parseName() string {
+	start := p.pos
+	for !p.eof() && isDotenvNameByte(p.peek(), p.pos == start) {
+		p.next()
+	}
+	return p.src[start:p.pos]
+}
+
+func isDotenvNameByte(c byte, first bool) bool {
+	switch {
+	case c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z':
+		return true
+	case '0' <= c && c <= '9' || c == '.' || c == '-':
+		return !first
+	}
+	return false
+}
+
+func (p *dotenvParser) parseSingleQuoted() (string, error) {
+	p.next()
+	start := p.pos
+	for !p.eof() {
+		if p.peek() == '\'' {
+			val := p.src[start:p.pos]
+			p.next()
+			return val, nil
+		}
+		p.next()
+	}
+	return "", fmt.Errorf("unterminated single quoted value")
+}
+
+func (p *dotenvParser) parseDoubleQuoted(vars map[string]string) (string, error) {
+	p.next()
+	var b strings.Builder
+	for !p.eof() {
+		switch c := p.next(); c {
+		case '"':
+			return b.String(), nil
+		case '\\':
+			if p.eof() {
+				break
+			}
+			switch e := p.next(); e {
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			default:
+				// \", \\, \$ and unknown escapes keep the escaped
+				// character.
+				b.WriteByte(e)
+			}
+		case '$':
+			if err := p.expand(&b, vars); err != nil {
+				return "", err
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated double quoted value")
+}
+
+// parseUnquoted reads the rest of the line, up to a comment that follows a
+// space, with surrounding spaces trimmed. afterSpace tells whether spaces
+// were skipped before the value.
+func (p *dotenvParser) parseUnquoted(vars map[string]string, afterSpace bool) string {
+	var b strings.Builder
+	for !p.eof() && p.peek() != '\n' {
+		c := p.next()
+		switch {
+		case c == '#' && afterSpace:
+			p.skipLine()
+			return strings.TrimSpace(b.String())
+		case c == '$':
+			// An unterminated ${ is kept as written.
+			if err := p.expand(&b, vars); err != nil {
+				b.WriteString("${")
+			}
+		default:
+			b.WriteByte(c)
+		}
+		afterSpace = c == ' ' || c == '\t'
+	}
+	if !p.eof() {
+		p.next()
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// expand writes the expansion of the variable reference following a $.
+func (p *dotenvParser) expand(b *strings.Builder, vars map[string]string) error {
+	if p.peek() == '{' {
+		end := strings.IndexAny(p.src[p.pos:], "}\n")
+		if end == -1 || p.src[p.pos+end] != '}' {
+			p.next()
+			return fmt.Errorf("unterminated variable reference")
+		}
+		ref := p.src[p.pos+1 : p.pos+end]
+		p.pos += end + 1
+		name, def, hasDefault := strings.Cut(ref, ":-")
+		if val := vars[name]; val != "" || !hasDefault {
+			b.WriteString(val)
+		} else {
+			b.WriteString(def)
+		}
+		return nil
+	}
+	// Unlike assignments, $NAME references stop at dots and dashes, so that
+	// $HOST-$PORT works.
+	start := p.pos
+	for c := p.peek(); c != '.' && c != '-' && isDotenvNameByte(c, p.pos == start); c = p.peek() {
+		p.next()
+	}
+	if name := p.src[start:p.pos]; name != "" {
+		b.WriteString(vars[name])
+		return nil
+	}
+	b.WriteByte('$')
+	return nil
+}
+
+// DotenvDecodeFunc constructs a function that parses a string in .env
+// syntax into a map of strings.
+var DotenvDecodeFunc = function.New(&function.Spec{
+	Description: "Parses a string in .env file syntax and returns a map of the variables it defines.",
+	Params: []function.Parameter{
+		{
+			Name:        "str",
+			Description: "The .env content",
+			Type:        cty.String,
+		},
+	},
+	Type: function.StaticReturnType(cty.Map(cty.String)),
+	RefineResult: func(builder *cty.RefinementBuilder) *cty.RefinementBuilder {
+		return builder.NotNull()
+	},
+	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+		vars, err := ParseDotenv([]byte(args[0].AsString()))
+		if err != nil {
+			return cty.UnknownVal(retType), function.NewArgError(0, err)
+		}
+		return dotenvVal(vars), nil
+	},
+})
+
+// MakeDotenvFileFunc constructs a function that reads the .env file at the
+// given path, from fsys or from the host OS when fsys is nil, and parses it
+// into a map of strings.
+func MakeDotenvFileFunc(fsys fs.FS, baseDir string) function.Function {
+	return dotenvFileFunc(fileSystem{fsys: fsys, baseDir: baseDir})
+}
+
+func dotenvFileFunc(files fileSystem) function.Function {
+	return function.New(&function.Spec{
+		Description: "Reads the .env file at the given path and returns a map of the variables it defines.",
+		Params: []function.Parameter{
+			{
+				Name:        "path",
+				Description: "Path of the .env file, relative to the base directory.",
+				Type:        cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.Map(cty.String)),
+		RefineResult: func(builder *cty.RefinementBuilder) *cty.RefinementBuilder {
+			return builder.NotNull()
+		},
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			path := args[0].AsString()
+			src, err := files.readFile(path)
+			if err != nil {
+				return cty.UnknownVal(retType), err
+			}
+			vars, err := ParseDotenv(src)
+			if err != nil {
+				return cty.UnknownVal(retType), fmt.Errorf("failed to parse %s: %w", path, err)
+			}
+			return dotenvVal(vars), nil
+		},
+	})
+}
+
+func dotenvVal(vars map[string]string) cty.Value {
+	if len(vars) == 0 {
+		return cty.MapValEmpty(cty.String)
+	}
+	vals := make(map[string]cty.Value, len(vars))
+	for k, v := range vars {
+		vals[k] = cty.StringVal(v)
+	}
+	return cty.MapVal(vals)
+}
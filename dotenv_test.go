@@ -0,0 +1,97 @@
+package hclfuncs
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestParseDotenv(t *testing.T) {
+	src := "# settings\r\n" +
+		"export HOST=example.com\n" +
+		"PORT = 8080 # inline comment\n" +
+		"HASH=a#b\n" +
+		"EMPTY=\n" +
+		"SINGLE='$HOST \\n # kept'\n" +
+		"DOUBLE=\"say \\\"hi\\\"\\tto ${HOST}\\n\" # comment\n" +
+		"MULTI=\"line one\n" +
+		"line two\"\n" +
+		"URL=https://$HOST:${PORT}/${PATH:-index}\n" +
+		"DASH=$HOST-$PORT\n" +
+		"ESCAPED=\"\\$HOST\"\n" +
+		"UNDEFINED=[$NOPE]\n" +
+		"   \n" +
+		"HOST=override\n"
+	vars, err := ParseDotenv([]byte(src))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"HOST":      "override",
+		"PORT":      "8080",
+		"HASH":      "a#b",
+		"EMPTY":     "",
+		"SINGLE":    `$HOST \n # kept`,
+		"DOUBLE":    "say \"hi\"\tto example.com\n",
+		"MULTI":     "line one\nline two",
+		"URL":       "https://example.com:8080/index",
+		"DASH":      "example.com-8080",
+		"ESCAPED":   "$HOST",
+		"UNDEFINED": "[]",
+	}, vars)
+}
+
+func TestParseDotenv_Errors(t *testing.T) {
+	cases := map[string]string{
+		"A=1\nB":                  "line 2: expected = after B",
+		"=1":                      `line 1: expected a variable name, found '='`,
+		"A='open\n\n":             "line 1: unterminated single quoted value",
+		"A=\"open":                "line 1: unterminated double quoted value",
+		"A=\"${B\"":               "line 1: unterminated variable reference",
+		"A=\"quoted\" trailing\n": "line 1: unexpected character 't' after value",
+	}
+	for src, expected := range cases {
+		_, err := ParseDotenv([]byte(src))
+		assert.EqualError(t, err, expected, src)
+	}
+}
+
+func TestDotenvFunctions(t *testing.T) {
+	fsys := fstest.MapFS{"conf/.env": {Data: []byte("NAME=app\nURL=http://${NAME}\n")}}
+	expected := cty.MapVal(map[string]cty.Value{
+		"NAME": cty.StringVal("app"),
+		"URL":  cty.StringVal("http://app"),
+	})
+
+	v, diag := evalFS(t, `dotenvfile(".env")`, nil, WithFS(fsys), WithBaseDir("conf"))
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.True(t, expected.RawEquals(v), v.GoString())
+
+	v, diag = evalFS(t, `dotenvdecode("NAME=app\nURL=http://$${NAME}")`, nil)
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.True(t, expected.RawEquals(v), v.GoString())
+
+	v, diag = evalFS(t, `dotenvdecode("")`, nil)
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.True(t, cty.MapValEmpty(cty.String).RawEquals(v))
+
+	_, diag = evalFS(t, `dotenvdecode("A='x")`, nil)
+	assert.ErrorContains(t, diag, "line 1: unterminated single quoted value")
+	_, diag = evalFS(t, `dotenvfile("missing.env")`, nil, WithFS(fsys), WithBaseDir("conf"))
+	assert.ErrorContains(t, diag, "no file exists at conf/missing.env")
+}
+
+func TestMergeGoroutineLocalEnv(t *testing.T) {
+	GoroutineLocalEnv.Set(map[string]string{"A": "local", "B": "local"})
+	defer GoroutineLocalEnv.Remove()
+	vars, err := ParseDotenv([]byte("B=dotenv\nC=dotenv"))
+	require.NoError(t, err)
+	MergeGoroutineLocalEnv(vars)
+
+	v, diag := evalFS(t, `[env("A"), env("B"), env("C")]`, nil)
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.True(t, cty.TupleVal([]cty.Value{
+		cty.StringVal("local"), cty.StringVal("dotenv"), cty.StringVal("dotenv"),
+	}).RawEquals(v), v.GoString())
+}
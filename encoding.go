@@ -13,6 +13,7 @@ import (
 // URLEncodeFunc constructs a function that applies URL encoding to a given string.
 // Copy from https://github.com/opentofu/opentofu/blob/v1.7.1/internal/lang/funcs/encoding.go
 var URLEncodeFunc = function.New(&function.Spec{
+	Description: "Applies URL encoding to a given string.",
 	Params: []function.Parameter{
 		{
 			Name: "str",
@@ -29,6 +30,7 @@ var URLEncodeFunc = function.New(&function.Spec{
 // URLDecodeFunc constructs a function that applies URL decoding to a given encoded string.
 // Copy from https://github.com/opentofu/opentofu/blob/v1.7.1/internal/lang/funcs/encoding.go
 var URLDecodeFunc = function.New(&function.Spec{
+	Description: "Applies URL decoding to a given encoded string.",
 	Params: []function.Parameter{
 		{
 			Name: "str",
@@ -50,6 +52,7 @@ var URLDecodeFunc = function.New(&function.Spec{
 // TextEncodeBase64Func constructs a function that encodes a string to a target encoding and then to a base64 sequence.
 // Copy from https://github.com/opentofu/opentofu/blob/v1.7.1/internal/lang/funcs/encoding.go
 var TextEncodeBase64Func = function.New(&function.Spec{
+	Description: "Encodes the unicode characters in a given string using a specified character encoding, returning the result base64 encoded.",
 	Params: []function.Parameter{
 		{
 			Name: "string",
@@ -95,6 +98,7 @@ var TextEncodeBase64Func = function.New(&function.Spec{
 // TextDecodeBase64Func constructs a function that decodes a base64 sequence to a target encoding.
 // Copy from https://github.com/opentofu/opentofu/blob/v1.7.1/internal/lang/funcs/encoding.go
 var TextDecodeBase64Func = function.New(&function.Spec{
+	Description: "Decodes a string that was previously Base64-encoded, and then interprets the result as characters in a specified character encoding.",
 	Params: []function.Parameter{
 		{
 			Name: "source",
@@ -0,0 +1,357 @@
+package hclfuncs
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/lonegunmanb/hclfuncs/marks"
+	"github.com/timandy/routine"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// GoroutineLocalEnv holds environment variable overrides for the current
+// goroutine. The default EnvProvider reads them before the process
+// environment. Overrides are not seen by other goroutines, including those
+// spawned during evaluation; bind a MapEnvProvider with WithEnvProvider when
+// that matters.
+var GoroutineLocalEnv = routine.NewThreadLocal[map[string]string]()
+
+// EnvProvider is the source of the environment variables read by env.
+type EnvProvider interface {
+	// LookupEnv returns the value of the variable named key and whether
+	// it is set.
+	LookupEnv(key string) (string, bool)
+	// Environ returns every variable, keyed by name.
+	Environ() map[string]string
+}
+
+// OSEnvProvider reads the environment of the process.
+type OSEnvProvider struct{}
+
+func (OSEnvProvider) LookupEnv(key string) (string, bool) {
+	return os.LookupEnv(key)
+}
+
+func (OSEnvProvider) Environ() map[string]string {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		// Windows has entries such as "=C:=C:\" that have no name.
+		if k, v, ok := strings.Cut(kv, "="); ok && k != "" {
+			env[k] = v
+		}
+	}
+	return env
+}
+
+// MapEnvProvider serves environment variables from a map.
+type MapEnvProvider map[string]string
+
+func (p MapEnvProvider) LookupEnv(key string) (string, bool) {
+	v, ok := p[key]
+	return v, ok
+}
+
+func (p MapEnvProvider) Environ() map[string]string {
+	env := make(map[string]string, len(p))
+	for k, v := range p {
+		env[k] = v
+	}
+	return env
+}
+
+// GoroutineLocalEnvProvider reads the overrides set in GoroutineLocalEnv
+// by the calling goroutine.
+type GoroutineLocalEnvProvider struct{}
+
+func (GoroutineLocalEnvProvider) LookupEnv(key string) (string, bool) {
+	return MapEnvProvider(GoroutineLocalEnv.Get()).LookupEnv(key)
+}
+
+func (GoroutineLocalEnvProvider) Environ() map[string]string {
+	return MapEnvProvider(GoroutineLocalEnv.Get()).Environ()
+}
+
+// LayeredEnvProvider looks variables up in each of its providers in turn;
+// the first provider that has a variable wins.
+type LayeredEnvProvider []EnvProvider
+
+func (p LayeredEnvProvider) LookupEnv(key string) (string, bool) {
+	for _, layer := range p {
+		if v, ok := layer.LookupEnv(key); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+func (p LayeredEnvProvider) Environ() map[string]string {
+	env := make(map[string]string)
+	for i := len(p) - 1; i >= 0; i-- {
+		for k, v := range p[i].Environ() {
+			env[k] = v
+		}
+	}
+	return env
+}
+
+// lookupEnvSource looks key up in provider and tells where it was found.
+func lookupEnvSource(provider EnvProvider, key string) (string, EnvSource, bool) {
+	var source EnvSource
+	switch p := provider.(type) {
+	case LayeredEnvProvider:
+		for _, layer := range p {
+			if v, source, ok := lookupEnvSource(layer, key); ok {
+				return v, source, true
+			}
+		}
+		return "", "", false
+	case GoroutineLocalEnvProvider:
+		source = EnvSourceGoroutineLocal
+	case OSEnvProvider:
+		source = EnvSourceOS
+	case MapEnvProvider:
+		source = EnvSourceMap
+	default:
+		source = EnvSourceOther
+	}
+	v, ok := provider.LookupEnv(key)
+	if !ok {
+		return "", "", false
+	}
+	return v, source, true
+}
+
+// envReader reads variables for the env functions, and records the reads
+// when recorder is set.
+type envReader struct {
+	provider EnvProvider
+	recorder *EnvRecorder
+	// sensitive holds the patterns of the names of sensitive variables.
+	sensitive []string
+}
+
+func (c *config) envReader() envReader {
+	return envReader{provider: c.env, recorder: c.envRec, sensitive: c.sensitiveEnv}
+}
+
+// mark marks val, read from the variable key, as sensitive when key matches
+// one of the sensitive patterns.
+func (r envReader) mark(key string, val cty.Value) cty.Value {
+	for _, pattern := range r.sensitive {
+		if ok, _ := path.Match(pattern, key); ok {
+			return val.Mark(marks.Sensitive)
+		}
+	}
+	return val
+}
+
+func (r envReader) lookup(key string) (string, bool) {
+	v, source, ok := lookupEnvSource(r.provider, key)
+	r.recorder.record(EnvRead{Key: key, Source: source, Set: ok}, v)
+	return v, ok
+}
+
+// withPrefix returns the variables whose names start with prefix, keyed by
+// the rest of their names.
+func (r envReader) withPrefix(prefix string) map[string]string {
+	env := make(map[string]string)
+	for k := range r.provider.Environ() {
+		if name, ok := strings.CutPrefix(k, prefix); ok && name != "" {
+			env[name], _ = r.lookup(k)
+		}
+	}
+	r.recorder.record(EnvRead{Key: prefix, Prefix: true, Set: len(env) > 0}, "")
+	return env
+}
+
+// defaultEnvProvider reads the goroutine-local overrides, then the process
+// environment.
+var defaultEnvProvider = LayeredEnvProvider{GoroutineLocalEnvProvider{}, OSEnvProvider{}}
+
+// WithEnvProvider sets the source of the variables read by env. By default
+// env reads GoroutineLocalEnv for the calling goroutine, then the process
+// environment. A provider bound here applies to every goroutine that calls
+// the table's functions. To keep the goroutine-local overrides on top of
+// another provider, layer them:
+//
+//	WithEnvProvider(LayeredEnvProvider{GoroutineLocalEnvProvider{}, MapEnvProvider(vars)})
+func WithEnvProvider(provider EnvProvider) Option {
+	return func(c *config) {
+		c.env = provider
+	}
+}
+
+// DefaultSensitiveEnvPatterns matches the names of variables that commonly
+// hold secrets, for use with WithSensitiveEnv.
+var DefaultSensitiveEnvPatterns = []string{"*_TOKEN", "*_PASSWORD", "*_SECRET", "*_API_KEY"}
+
+// WithSensitiveEnv marks the values that env, envrequired and envmap read
+// from variables whose names match one of patterns with marks.Sensitive.
+// Patterns use the syntax of path.Match, such as *_TOKEN, and are case
+// sensitive. Calls add to the patterns given before.
+func WithSensitiveEnv(patterns ...string) Option {
+	return func(c *config) {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				c.errs = append(c.errs, fmt.Errorf("invalid sensitive env pattern %q: %w", pattern, err))
+			}
+		}
+		c.sensitiveEnv = append(c.sensitiveEnv, patterns...)
+	}
+}
+
+// EnvFunction reads environment variables from GoroutineLocalEnv, then from
+// the process environment.
+var EnvFunction = MakeEnvFunc(defaultEnvProvider)
+
+// EnvRequiredFunc, EnvExistsFunc and EnvMapFunc are the envrequired,
+// envexists and envmap functions reading from GoroutineLocalEnv, then from
+// the process environment.
+var (
+	EnvRequiredFunc = MakeEnvRequiredFunc(defaultEnvProvider)
+	EnvExistsFunc   = MakeEnvExistsFunc(defaultEnvProvider)
+	EnvMapFunc      = MakeEnvMapFunc(defaultEnvProvider)
+)
+
+// MakeEnvFunc constructs an env function that reads from provider. It takes
+// an optional default value, returned when the variable is not set.
+func MakeEnvFunc(provider EnvProvider) function.Function {
+	return envFunc(envReader{provider: provider})
+}
+
+func envFunc(env envReader) function.Function {
+	return function.New(&function.Spec{
+		Description: "Read environment variable, return the default value, or an empty string when no default is given, if the variable is not set.",
+		Params: []function.Parameter{
+			{
+				Name:         "key",
+				Description:  "Environment variable name",
+				Type:         cty.String,
+				AllowUnknown: true,
+				AllowMarked:  true,
+			},
+		},
+		// cty has no optional parameters, so the default is variadic and
+		// Type rejects more than one.
+		VarParam: &function.Parameter{
+			Name:        "default",
+			Description: "Optional value returned when the variable is not set, at most one may be given",
+			Type:        cty.String,
+		},
+		Type: func(args []cty.Value) (cty.Type, error) {
+			if len(args) > 2 {
+				return cty.NilType, function.NewArgErrorf(2, "env takes at most one default value")
+			}
+			return cty.String, nil
+		},
+		RefineResult: func(builder *cty.RefinementBuilder) *cty.RefinementBuilder {
+			return builder.NotNull()
+		},
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			// A sensitive key makes the value it selects sensitive too.
+			key, keyMarks := args[0].Unmark()
+			if !key.IsKnown() {
+				return cty.UnknownVal(cty.String).WithMarks(keyMarks), nil
+			}
+			val, ok := env.lookup(key.AsString())
+			if !ok && len(args) > 1 {
+				return env.mark(key.AsString(), args[1]).WithMarks(keyMarks), nil
+			}
+			return env.mark(key.AsString(), cty.StringVal(val)).WithMarks(keyMarks), nil
+		},
+	})
+}
+
+// MakeEnvRequiredFunc constructs an envrequired function that reads from
+// provider.
+func MakeEnvRequiredFunc(provider EnvProvider) function.Function {
+	return envRequiredFunc(envReader{provider: provider})
+}
+
+func envRequiredFunc(env envReader) function.Function {
+	return function.New(&function.Spec{
+		Description: "Reads an environment variable, failing if the variable is not set.",
+		Params: []function.Parameter{
+			{
+				Name:        "key",
+				Description: "Environment variable name",
+				Type:        cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		RefineResult: func(builder *cty.RefinementBuilder) *cty.RefinementBuilder {
+			return builder.NotNull()
+		},
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			key := args[0].AsString()
+			val, ok := env.lookup(key)
+			if !ok {
+				return cty.UnknownVal(cty.String), function.NewArgErrorf(0, "environment variable %q is required but not set", key)
+			}
+			return env.mark(key, cty.StringVal(val)), nil
+		},
+	})
+}
+
+// MakeEnvExistsFunc constructs an envexists function that reads from
+// provider.
+func MakeEnvExistsFunc(provider EnvProvider) function.Function {
+	return envExistsFunc(envReader{provider: provider})
+}
+
+func envExistsFunc(env envReader) function.Function {
+	return function.New(&function.Spec{
+		Description: "Determines whether an environment variable is set, even to an empty string.",
+		Params: []function.Parameter{
+			{
+				Name:        "key",
+				Description: "Environment variable name",
+				Type:        cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.Bool),
+		RefineResult: func(builder *cty.RefinementBuilder) *cty.RefinementBuilder {
+			return builder.NotNull()
+		},
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			_, ok := env.lookup(args[0].AsString())
+			return cty.BoolVal(ok), nil
+		},
+	})
+}
+
+// MakeEnvMapFunc constructs an envmap function that reads from provider.
+func MakeEnvMapFunc(provider EnvProvider) function.Function {
+	return envMapFunc(envReader{provider: provider})
+}
+
+func envMapFunc(env envReader) function.Function {
+	return function.New(&function.Spec{
+		Description: "Returns the environment variables whose names start with the given prefix, as a map keyed by the rest of their names.",
+		Params: []function.Parameter{
+			{
+				Name:        "prefix",
+				Description: "Prefix of the variable names, stripped from the keys of the result",
+				Type:        cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.Map(cty.String)),
+		RefineResult: func(builder *cty.RefinementBuilder) *cty.RefinementBuilder {
+			return builder.NotNull()
+		},
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			prefix := args[0].AsString()
+			vals := make(map[string]cty.Value)
+			for name, v := range env.withPrefix(prefix) {
+				vals[name] = env.mark(prefix+name, cty.StringVal(v))
+			}
+			if len(vals) == 0 {
+				return cty.MapValEmpty(cty.String), nil
+			}
+			return cty.MapVal(vals), nil
+		},
+	})
+}
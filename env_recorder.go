@@ -0,0 +1,114 @@
+package hclfuncs
+
+import (
+	"sort"
+	"sync"
+)
+
+// EnvSource tells where an environment variable was found.
+type EnvSource string
+
+const (
+	// EnvSourceGoroutineLocal is GoroutineLocalEnv.
+	EnvSourceGoroutineLocal EnvSource = "goroutine-local"
+	// EnvSourceOS is the process environment.
+	EnvSourceOS EnvSource = "os"
+	// EnvSourceMap is a MapEnvProvider.
+	EnvSourceMap EnvSource = "map"
+	// EnvSourceOther is a custom EnvProvider.
+	EnvSourceOther EnvSource = "other"
+)
+
+// redactedEnvValue stands in for the values of recorded variables unless the
+// recorder reveals them.
+const redactedEnvValue = "(redacted)"
+
+// EnvRead describes an environment variable read by env, envrequired,
+// envexists or envmap.
+type EnvRead struct {
+	// Key is the name of the variable, or the prefix given to envmap when
+	// Prefix is true.
+	Key string `json:"key"`
+	// Prefix is true for envmap calls. Each variable envmap returned is
+	// recorded as a read of its own.
+	Prefix bool `json:"prefix,omitempty"`
+	// Set reports whether the variable was set, or whether any variable
+	// had the prefix.
+	Set bool `json:"set"`
+	// Source is where the variable was found, empty when it was not set.
+	Source EnvSource `json:"source,omitempty"`
+	// Value is the value of the variable, "(redacted)" unless the
+	// recorder reveals values, and empty when the variable was not set or
+	// for prefixes.
+	Value string `json:"value,omitempty"`
+}
+
+// EnvRecorder records the environment variables that the env functions of a
+// table read, so that callers can tell which variables a configuration
+// depends on. It is safe for concurrent use. Bind it with WithEnvRecorder,
+// evaluate, then call Reads; use a recorder per evaluation, or call Reset in
+// between.
+type EnvRecorder struct {
+	// RevealValues records the values of variables instead of redacting
+	// them.
+	RevealValues bool
+
+	mu    sync.Mutex
+	reads map[envReadKey]EnvRead
+}
+
+type envReadKey struct {
+	key    string
+	prefix bool
+}
+
+// WithEnvRecorder makes the env functions of the table record their reads
+// in recorder.
+func WithEnvRecorder(recorder *EnvRecorder) Option {
+	return func(c *config) {
+		c.envRec = recorder
+	}
+}
+
+// Reads returns the variables read since the recorder was created or reset,
+// sorted by key. A variable read several times is listed once.
+func (r *EnvRecorder) Reads() []EnvRead {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	reads := make([]EnvRead, 0, len(r.reads))
+	for _, read := range r.reads {
+		reads = append(reads, read)
+	}
+	sort.Slice(reads, func(i, j int) bool {
+		if reads[i].Key != reads[j].Key {
+			return reads[i].Key < reads[j].Key
+		}
+		return !reads[i].Prefix
+	})
+	return reads
+}
+
+// Reset forgets every recorded read.
+func (r *EnvRecorder) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reads = nil
+}
+
+func (r *EnvRecorder) record(read EnvRead, value string) {
+	if r == nil {
+		return
+	}
+	if read.Set && !read.Prefix {
+		read.Value = redactedEnvValue
+		if r.RevealValues {
+			read.Value = value
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.reads == nil {
+		r.reads = make(map[envReadKey]EnvRead)
+	}
+	r.reads[envReadKey{key: read.Key, prefix: read.Prefix}] = read
+}
@@ -0,0 +1,43 @@
+package hclfuncs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvRecorder(t *testing.T) {
+	t.Setenv("HCLFUNCS_TEST_OS", "os-value")
+	GoroutineLocalEnv.Set(map[string]string{"HCLFUNCS_TEST_LOCAL": "local-value"})
+	defer GoroutineLocalEnv.Remove()
+
+	recorder := &EnvRecorder{}
+	code := `[env("HCLFUNCS_TEST_OS"), env("HCLFUNCS_TEST_UNSET", "x"), envexists("HCLFUNCS_TEST_LOCAL"), envmap("HCLFUNCS_TEST_L"), env("HCLFUNCS_TEST_OS")]`
+	_, diag := evalFS(t, code, nil, WithEnvRecorder(recorder))
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, []EnvRead{
+		{Key: "HCLFUNCS_TEST_L", Prefix: true, Set: true},
+		{Key: "HCLFUNCS_TEST_LOCAL", Set: true, Source: EnvSourceGoroutineLocal, Value: "(redacted)"},
+		{Key: "HCLFUNCS_TEST_OS", Set: true, Source: EnvSourceOS, Value: "(redacted)"},
+		{Key: "HCLFUNCS_TEST_UNSET"},
+	}, recorder.Reads())
+
+	recorder.Reset()
+	assert.Empty(t, recorder.Reads())
+}
+
+func TestEnvRecorder_RevealValues(t *testing.T) {
+	recorder := &EnvRecorder{RevealValues: true}
+	provider := LayeredEnvProvider{MapEnvProvider{"A": "a"}, OSEnvProvider{}}
+	_, diag := evalFS(t, `envrequired("A")`, nil, WithEnvProvider(provider), WithEnvRecorder(recorder))
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, []EnvRead{{Key: "A", Set: true, Source: EnvSourceMap, Value: "a"}}, recorder.Reads())
+}
+
+func TestEnvRecorder_UnsetHasNoSource(t *testing.T) {
+	recorder := &EnvRecorder{}
+	_, diag := evalFS(t, `env("NOPE_X")`, nil, WithEnvProvider(MapEnvProvider{}), WithEnvRecorder(recorder))
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, []EnvRead{{Key: "NOPE_X"}}, recorder.Reads())
+}
@@ -0,0 +1,149 @@
+package hclfuncs
+
+import (
+	"testing"
+
+	"github.com/lonegunmanb/hclfuncs/marks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestEnvProvider_Layered(t *testing.T) {
+	p := LayeredEnvProvider{
+		MapEnvProvider{"A": "top", "EMPTY": ""},
+		MapEnvProvider{"A": "bottom", "B": "bottom"},
+	}
+	v, ok := p.LookupEnv("A")
+	assert.True(t, ok)
+	assert.Equal(t, "top", v)
+	v, ok = p.LookupEnv("EMPTY")
+	assert.True(t, ok)
+	assert.Equal(t, "", v)
+	_, ok = p.LookupEnv("C")
+	assert.False(t, ok)
+	assert.Equal(t, map[string]string{"A": "top", "B": "bottom", "EMPTY": ""}, p.Environ())
+}
+
+func TestEnvProvider_OS(t *testing.T) {
+	t.Setenv("HCLFUNCS_TEST_ENV", "a=b")
+	v, ok := OSEnvProvider{}.LookupEnv("HCLFUNCS_TEST_ENV")
+	assert.True(t, ok)
+	assert.Equal(t, "a=b", v)
+	assert.Equal(t, "a=b", OSEnvProvider{}.Environ()["HCLFUNCS_TEST_ENV"])
+}
+
+func TestEnvProvider_GoroutineLocal(t *testing.T) {
+	GoroutineLocalEnv.Set(map[string]string{"A": "local"})
+	defer GoroutineLocalEnv.Remove()
+	v, ok := GoroutineLocalEnvProvider{}.LookupEnv("A")
+	assert.True(t, ok)
+	assert.Equal(t, "local", v)
+
+	done := make(chan bool)
+	go func() {
+		_, ok := GoroutineLocalEnvProvider{}.LookupEnv("A")
+		done <- ok
+	}()
+	assert.False(t, <-done)
+}
+
+func TestWithEnvProvider(t *testing.T) {
+	t.Setenv("HCLFUNCS_TEST_ENV", "os")
+	funcs, err := NewFunctions(WithEnvProvider(MapEnvProvider{"A": "map"}))
+	require.NoError(t, err)
+
+	// The bound provider is seen from any goroutine.
+	done := make(chan cty.Value)
+	go func() {
+		v, err := funcs["env"].Call([]cty.Value{cty.StringVal("A")})
+		assert.NoError(t, err)
+		done <- v
+	}()
+	assert.Equal(t, "map", (<-done).AsString())
+
+	v, err := funcs["env"].Call([]cty.Value{cty.StringVal("HCLFUNCS_TEST_ENV")})
+	require.NoError(t, err)
+	assert.Equal(t, "", v.AsString())
+}
+
+func TestEnvFunctions(t *testing.T) {
+	opts := []Option{WithEnvProvider(MapEnvProvider{
+		"APP_NAME":   "demo",
+		"APP_EMPTY":  "",
+		"APP_":       "nameless",
+		"OTHER_NAME": "other",
+	})}
+	cases := map[string]cty.Value{
+		`env("APP_NAME", "fallback")`:  cty.StringVal("demo"),
+		`env("APP_EMPTY", "fallback")`: cty.StringVal(""),
+		`env("APP_UNSET", "fallback")`: cty.StringVal("fallback"),
+		`env("APP_UNSET")`:             cty.StringVal(""),
+		`envrequired("APP_EMPTY")`:     cty.StringVal(""),
+		`envexists("APP_EMPTY")`:       cty.True,
+		`envexists("APP_UNSET")`:       cty.False,
+		`envmap("APP_")`: cty.MapVal(map[string]cty.Value{
+			"NAME":  cty.StringVal("demo"),
+			"EMPTY": cty.StringVal(""),
+		}),
+		`envmap("NONE_")`: cty.MapValEmpty(cty.String),
+	}
+	for code, expected := range cases {
+		t.Run(code, func(t *testing.T) {
+			v, diag := evalFS(t, code, nil, opts...)
+			require.False(t, diag.HasErrors(), diag.Error())
+			assert.True(t, expected.RawEquals(v), v.GoString())
+		})
+	}
+
+	_, diag := evalFS(t, `envrequired("APP_UNSET")`, nil, opts...)
+	assert.ErrorContains(t, diag, `environment variable "APP_UNSET" is required but not set`)
+	_, diag = evalFS(t, `env("APP_NAME", "a", "b")`, nil, opts...)
+	assert.ErrorContains(t, diag, "env takes at most one default value")
+}
+
+func TestEnvFunctions_HonorGoroutineLocalEnv(t *testing.T) {
+	t.Setenv("HCLFUNCS_TEST_ENV", "os")
+	GoroutineLocalEnv.Set(map[string]string{"HCLFUNCS_TEST_LOCAL": "local"})
+	defer GoroutineLocalEnv.Remove()
+
+	v, diag := evalFS(t, `envrequired("HCLFUNCS_TEST_LOCAL")`, nil)
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, "local", v.AsString())
+
+	v, diag = evalFS(t, `envmap("HCLFUNCS_TEST_")`, nil)
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.True(t, cty.MapVal(map[string]cty.Value{
+		"ENV":   cty.StringVal("os"),
+		"LOCAL": cty.StringVal("local"),
+	}).RawEquals(v), v.GoString())
+}
+
+func TestWithSensitiveEnv(t *testing.T) {
+	opts := []Option{
+		WithEnvProvider(MapEnvProvider{"APP_TOKEN": "s3cr3t", "APP_NAME": "demo"}),
+		WithSensitiveEnv(DefaultSensitiveEnvPatterns...),
+	}
+	cases := map[string]cty.Value{
+		`env("APP_TOKEN")`:               cty.StringVal("s3cr3t").Mark(marks.Sensitive),
+		`env("APP_NAME")`:                cty.StringVal("demo"),
+		`env("OTHER_TOKEN", "x")`:        cty.StringVal("x").Mark(marks.Sensitive),
+		`envrequired("APP_TOKEN")`:       cty.StringVal("s3cr3t").Mark(marks.Sensitive),
+		`envexists("APP_TOKEN")`:         cty.True,
+		`envmap("APP_")["TOKEN"]`:        cty.StringVal("s3cr3t").Mark(marks.Sensitive),
+		`envmap("APP_")["NAME"]`:         cty.StringVal("demo"),
+		`issensitive(envmap("APP_"))`:    cty.False,
+		`env(sensitive("APP_NAME"))`:     cty.StringVal("demo").Mark(marks.Sensitive),
+		`nonsensitive(env("APP_TOKEN"))`: cty.StringVal("s3cr3t"),
+	}
+	for code, expected := range cases {
+		t.Run(code, func(t *testing.T) {
+			v, diag := evalFS(t, code, nil, opts...)
+			require.False(t, diag.HasErrors(), diag.Error())
+			assert.True(t, expected.RawEquals(v), v.GoString())
+		})
+	}
+
+	_, err := NewFunctions(WithSensitiveEnv("[A-"))
+	assert.ErrorContains(t, err, `invalid sensitive env pattern "[A-"`)
+}
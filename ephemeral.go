@@ -0,0 +1,60 @@
+package hclfuncs
+
+import (
+	"github.com/lonegunmanb/hclfuncs/marks"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// EphemeralAsNullFunc returns a copy of its argument where every ephemeral
+// value, including those nested in collections, is replaced by a null of
+// the same type, so that the result can be persisted.
+var EphemeralAsNullFunc = function.New(&function.Spec{
+	Description: "Takes any value and returns a copy of it with every ephemeral value replaced by null.",
+	Params: []function.Parameter{
+		{
+			Name:             "value",
+			Type:             cty.DynamicPseudoType,
+			AllowUnknown:     true,
+			AllowNull:        true,
+			AllowMarked:      true,
+			AllowDynamicType: true,
+		},
+	},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		// Nulls take the type of the values they replace, so the result
+		// type is always the same as the argument type.
+		return args[0].Type(), nil
+	},
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		return cty.Transform(args[0], func(_ cty.Path, v cty.Value) (cty.Value, error) {
+			if !v.HasMark(marks.Ephemeral) {
+				return v, nil
+			}
+			_, m := v.Unmark()
+			delete(m, marks.Ephemeral)
+			return cty.NullVal(v.Type()).WithMarks(m), nil
+		})
+	},
+})
+
+// IsEphemeralFunc returns whether or not the value is ephemeral.
+var IsEphemeralFunc = function.New(&function.Spec{
+	Description: "Returns `true` if the given value is marked as ephemeral.",
+	Params: []function.Parameter{
+		{
+			Name:             "value",
+			Type:             cty.DynamicPseudoType,
+			AllowUnknown:     true,
+			AllowNull:        true,
+			AllowMarked:      true,
+			AllowDynamicType: true,
+		},
+	},
+	Type: func(args []cty.Value) (cty.Type, error) {
+		return cty.Bool, nil
+	},
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		return cty.BoolVal(args[0].HasMark(marks.Ephemeral)), nil
+	},
+})
@@ -0,0 +1,39 @@
+package hclfuncs
+
+import (
+	"testing"
+
+	"github.com/lonegunmanb/hclfuncs/marks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestEphemeralAsNull(t *testing.T) {
+	val := cty.ObjectVal(map[string]cty.Value{
+		"name":  cty.StringVal("app"),
+		"token": cty.StringVal("s3cr3t").Mark(marks.Ephemeral).Mark(marks.Sensitive),
+		"ports": cty.ListVal([]cty.Value{cty.NumberIntVal(80).Mark(marks.Ephemeral)}),
+	})
+	v, err := EphemeralAsNullFunc.Call([]cty.Value{val})
+	require.NoError(t, err)
+	expected := cty.ObjectVal(map[string]cty.Value{
+		"name":  cty.StringVal("app"),
+		"token": cty.NullVal(cty.String).Mark(marks.Sensitive),
+		"ports": cty.ListVal([]cty.Value{cty.NullVal(cty.Number)}),
+	})
+	assert.True(t, expected.RawEquals(v), v.GoString())
+
+	v, err = EphemeralAsNullFunc.Call([]cty.Value{cty.UnknownVal(cty.String).Mark(marks.Ephemeral)})
+	require.NoError(t, err)
+	assert.True(t, cty.NullVal(cty.String).RawEquals(v), v.GoString())
+}
+
+func TestIsEphemeral(t *testing.T) {
+	v, err := IsEphemeralFunc.Call([]cty.Value{cty.StringVal("a").Mark(marks.Ephemeral)})
+	require.NoError(t, err)
+	assert.True(t, v.True())
+	v, err = IsEphemeralFunc.Call([]cty.Value{cty.StringVal("a").Mark(marks.Sensitive)})
+	require.NoError(t, err)
+	assert.False(t, v.True())
+}
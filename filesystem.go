@@ -0,0 +1,375 @@
+// Copy from https://github.com/hashicorp/go-cty-funcs/blob/a090f58aa992/filesystem/filesystem.go
+// with an fs.FS based implementation added next to the host OS one.
+package hclfuncs
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bmatcuk/doublestar"
+	homedir "github.com/mitchellh/go-homedir"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// WithFS makes file functions read from fsys instead of the host OS. Paths
+// are slash separated; relative paths are resolved against the base
+// directory, which is then a directory inside fsys, and absolute paths
+// against the root of fsys. Paths that escape fsys are rejected.
+func WithFS(fsys fs.FS) Option {
+	return func(c *config) {
+		c.fsys = fsys
+	}
+}
+
+// fileSystem is where file functions read from: the host OS when fsys is
+// nil, otherwise fsys. Reads honor the context supplied by io.
+type fileSystem struct {
+	fsys    fs.FS
+	baseDir string
+	io      ioContext
+}
+
+// files returns the file system that the file functions of c read from.
+func (c *config) files() fileSystem {
+	return fileSystem{fsys: c.fsys, baseDir: c.baseDir, io: c.io}
+}
+
+func (s fileSystem) resolve(p string) (string, error) {
+	if s.fsys == nil {
+		p, err := homedir.Expand(p)
+		if err != nil {
+			return "", fmt.Errorf("failed to expand ~: %s", err)
+		}
+
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(s.baseDir, p)
+		}
+
+		// Ensure that the path is canonical for the host OS
+		return filepath.Clean(p), nil
+	}
+
+	// path.Join cleans the joined path, so any ".." left over afterwards
+	// points outside of the file system.
+	name := path.Join(s.baseDir, p)
+	if path.IsAbs(p) {
+		name = path.Clean(p)
+	}
+	if name == ".." || strings.HasPrefix(name, "../") {
+		return "", fmt.Errorf("%s is outside of the file system", p)
+	}
+	name = strings.TrimPrefix(name, "/")
+	if name == "" {
+		name = "."
+	}
+	return name, nil
+}
+
+func (s fileSystem) stat(name string) (fs.FileInfo, error) {
+	return ioRun(s.io, "reading "+name, func(context.Context) (fs.FileInfo, error) {
+		if s.fsys == nil {
+			return os.Stat(name)
+		}
+		return fs.Stat(s.fsys, name)
+	})
+}
+
+func (s fileSystem) readFile(p string) ([]byte, error) {
+	name, err := s.resolve(p)
+	if err != nil {
+		return nil, err
+	}
+
+	return ioRun(s.io, "reading "+name, func(ctx context.Context) ([]byte, error) {
+		var f fs.File
+		var err error
+		if s.fsys == nil {
+			f, err = os.Open(name)
+		} else {
+			f, err = s.fsys.Open(name)
+		}
+		var src []byte
+		if err == nil {
+			src, err = io.ReadAll(contextReader{ctx: ctx, r: f})
+			f.Close()
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			// Open and Read do not return Terraform-user-friendly error
+			// messages, so we'll provide our own.
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil, fmt.Errorf("no file exists at %s", name)
+			}
+			return nil, fmt.Errorf("failed to read %s", name)
+		}
+
+		return src, nil
+	})
+}
+
+// glob returns the regular files under dir matching pattern, relative to dir
+// and slash separated.
+func (s fileSystem) glob(dir, pattern string) ([]string, error) {
+	return ioRun(s.io, fmt.Sprintf("listing files matching %s in %s", pattern, dir), func(ctx context.Context) ([]string, error) {
+		if s.fsys == nil {
+			return s.globOS(dir, pattern)
+		}
+		return s.globFS(ctx, dir, pattern)
+	})
+}
+
+func (s fileSystem) globOS(dir, pattern string) ([]string, error) {
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(s.baseDir, dir)
+	}
+
+	// Join the path to the glob pattern, while ensuring the full
+	// pattern is canonical for the host OS. The joined path is
+	// automatically cleaned during this operation.
+	pattern = filepath.Join(dir, pattern)
+
+	matches, err := doublestar.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to glob pattern (%s): %s", pattern, err)
+	}
+
+	var r []string
+	for _, match := range matches {
+		fi, err := os.Stat(match)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat (%s): %s", match, err)
+		}
+
+		if !fi.Mode().IsRegular() {
+			continue
+		}
+
+		// Remove the path and file separator from matches.
+		match, err = filepath.Rel(dir, match)
+
+		if err != nil {
+			return nil, fmt.Errorf("failed to trim path of match (%s): %s", match, err)
+		}
+
+		// Replace any remaining file separators with forward slash (/)
+		// separators for cross-system compatibility.
+		r = append(r, filepath.ToSlash(match))
+	}
+	return r, nil
+}
+
+// globFS walks fsys, so it can stop as soon as ctx is done.
+func (s fileSystem) globFS(ctx context.Context, dir, pattern string) ([]string, error) {
+	root, err := s.resolve(dir)
+	if err != nil {
+		return nil, err
+	}
+	pattern = path.Join(root, pattern)
+	var r []string
+	err = fs.WalkDir(s.fsys, root, func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if name == root && errors.Is(err, fs.ErrNotExist) {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		matched, err := doublestar.Match(pattern, name)
+		if err != nil {
+			return fmt.Errorf("failed to glob pattern (%s): %s", pattern, err)
+		}
+		if !matched {
+			return nil
+		}
+		fi, err := fs.Stat(s.fsys, name)
+		if err != nil {
+			return fmt.Errorf("failed to stat (%s): %s", name, err)
+		}
+		if !fi.Mode().IsRegular() {
+			return nil
+		}
+		if root != "." {
+			name = strings.TrimPrefix(name, root+"/")
+		}
+		r = append(r, name)
+		return nil
+	})
+	return r, err
+}
+
+// MakeFileFunc constructs a function that takes a file path and returns the
+// contents of that file, either directly as a string (where valid UTF-8 is
+// required) or as a string containing base64 bytes. The file is read from
+// fsys, or from the host OS when fsys is nil.
+func MakeFileFunc(fsys fs.FS, baseDir string, encBase64 bool) function.Function {
+	return fileFunc(fileSystem{fsys: fsys, baseDir: baseDir}, encBase64)
+}
+
+func fileFunc(files fileSystem, encBase64 bool) function.Function {
+	return function.New(&function.Spec{
+		Description: fileFuncDescription(encBase64),
+		Params: []function.Parameter{
+			{
+				Name: "path",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			path := args[0].AsString()
+			src, err := files.readFile(path)
+			if err != nil {
+				return cty.UnknownVal(cty.String), err
+			}
+
+			switch {
+			case encBase64:
+				enc := base64.StdEncoding.EncodeToString(src)
+				return cty.StringVal(enc), nil
+			default:
+				if !utf8.Valid(src) {
+					return cty.UnknownVal(cty.String), fmt.Errorf("contents of %s are not valid UTF-8; use the filebase64 function to obtain the Base64 encoded contents or the other file functions (e.g. filemd5, filesha256) to obtain file hashing results instead", path)
+				}
+				return cty.StringVal(string(src)), nil
+			}
+		},
+	})
+}
+
+func fileFuncDescription(encBase64 bool) string {
+	if encBase64 {
+		return "Reads the contents of a file at the given path and returns them as a base64-encoded string."
+	}
+	return "Reads the contents of a file at the given path and returns them as a string."
+}
+
+// MakeFileExistsFunc constructs a function that takes a path and determines
+// whether a file exists at that path in fsys, or in the host OS when fsys is
+// nil.
+func MakeFileExistsFunc(fsys fs.FS, baseDir string) function.Function {
+	return fileExistsFunc(fileSystem{fsys: fsys, baseDir: baseDir})
+}
+
+func fileExistsFunc(files fileSystem) function.Function {
+	return function.New(&function.Spec{
+		Description: "Determines whether a file exists at a given path.",
+		Params: []function.Parameter{
+			{
+				Name: "path",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.Bool),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			path, err := files.resolve(args[0].AsString())
+			if err != nil {
+				return cty.UnknownVal(cty.Bool), err
+			}
+
+			fi, err := files.stat(path)
+			if err != nil {
+				if errors.Is(err, fs.ErrNotExist) {
+					return cty.False, nil
+				}
+				if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+					return cty.UnknownVal(cty.Bool), err
+				}
+				return cty.UnknownVal(cty.Bool), fmt.Errorf("failed to stat %s", path)
+			}
+
+			if fi.Mode().IsRegular() {
+				return cty.True, nil
+			}
+
+			return cty.False, fmt.Errorf("%s is not a regular file, but %q",
+				path, fi.Mode().String())
+		},
+	})
+}
+
+// MakeFileSetFunc constructs a function that takes a glob pattern and
+// enumerates a file set from that pattern in fsys, or in the host OS when
+// fsys is nil.
+func MakeFileSetFunc(fsys fs.FS, baseDir string) function.Function {
+	return fileSetFunc(fileSystem{fsys: fsys, baseDir: baseDir})
+}
+
+func fileSetFunc(files fileSystem) function.Function {
+	return function.New(&function.Spec{
+		Description: "Enumerates a set of regular file names given a path and pattern.",
+		Params: []function.Parameter{
+			{
+				Name: "path",
+				Type: cty.String,
+			},
+			{
+				Name: "pattern",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.Set(cty.String)),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			matches, err := files.glob(args[0].AsString(), args[1].AsString())
+			if err != nil {
+				return cty.UnknownVal(cty.Set(cty.String)), err
+			}
+
+			if len(matches) == 0 {
+				return cty.SetValEmpty(cty.String), nil
+			}
+
+			var matchVals []cty.Value
+			for _, match := range matches {
+				matchVals = append(matchVals, cty.StringVal(match))
+			}
+			return cty.SetVal(matchVals), nil
+		},
+	})
+}
+
+// MakeAbsPathFunc constructs a function that converts a filesystem path to an
+// absolute path. For the host OS (fsys is nil) relative paths are resolved
+// against the working directory, like Terraform does; for fsys they are
+// resolved against baseDir and the result is rooted at "/".
+func MakeAbsPathFunc(fsys fs.FS, baseDir string) function.Function {
+	files := fileSystem{fsys: fsys, baseDir: baseDir}
+	return function.New(&function.Spec{
+		Description: "Takes a string containing a filesystem path and converts it to an absolute path.",
+		Params: []function.Parameter{
+			{
+				Name: "path",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			if files.fsys == nil {
+				absPath, err := filepath.Abs(args[0].AsString())
+				return cty.StringVal(filepath.ToSlash(absPath)), err
+			}
+			name, err := files.resolve(args[0].AsString())
+			if err != nil {
+				return cty.UnknownVal(cty.String), err
+			}
+			return cty.StringVal(path.Join("/", name)), nil
+		},
+	})
+}
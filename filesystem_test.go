@@ -0,0 +1,143 @@
+package hclfuncs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+var testFS = fstest.MapFS{
+	"README.md":            {Data: []byte("root")},
+	"conf/app.hcl":         {Data: []byte("app")},
+	"conf/db.hcl":          {Data: []byte("db")},
+	"conf/nested/deep.hcl": {Data: []byte("deep")},
+	"conf/notes.txt":       {Data: []byte("notes")},
+	"conf/binary.bin":      {Data: []byte{0xff, 0xfe}},
+}
+
+func evalFS(t *testing.T, code string, vars map[string]cty.Value, opts ...Option) (cty.Value, hcl.Diagnostics) {
+	funcs, err := NewFunctions(opts...)
+	require.NoError(t, err)
+	exp, diag := hclsyntax.ParseExpression([]byte(code), "test.hcl", hcl.InitialPos)
+	require.False(t, diag.HasErrors())
+	return exp.Value(&hcl.EvalContext{Functions: funcs, Variables: vars})
+}
+
+func TestFS_File(t *testing.T) {
+	v, diag := evalFS(t, `file("app.hcl")`, nil, WithFS(testFS), WithBaseDir("conf"))
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, "app", v.AsString())
+
+	v, diag = evalFS(t, `file("/README.md")`, nil, WithFS(testFS), WithBaseDir("conf"))
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, "root", v.AsString())
+
+	v, diag = evalFS(t, `file("../README.md")`, nil, WithFS(testFS), WithBaseDir("conf"))
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, "root", v.AsString())
+
+	_, diag = evalFS(t, `file("../../etc/passwd")`, nil, WithFS(testFS), WithBaseDir("conf"))
+	assert.ErrorContains(t, diag, "outside of the file system")
+
+	_, diag = evalFS(t, `file("missing.hcl")`, nil, WithFS(testFS), WithBaseDir("conf"))
+	assert.ErrorContains(t, diag, "no file exists at conf/missing.hcl")
+
+	_, diag = evalFS(t, `file("binary.bin")`, nil, WithFS(testFS), WithBaseDir("conf"))
+	assert.ErrorContains(t, diag, "not valid UTF-8")
+}
+
+func TestFS_FileExists(t *testing.T) {
+	cases := map[string]bool{
+		`fileexists("conf/app.hcl")`:  true,
+		`fileexists("conf/none.hcl")`: false,
+		`fileexists("/README.md")`:    true,
+	}
+	for code, expected := range cases {
+		v, diag := evalFS(t, code, nil, WithFS(testFS))
+		require.False(t, diag.HasErrors(), diag.Error())
+		assert.Equal(t, expected, v.True(), code)
+	}
+	_, diag := evalFS(t, `fileexists("conf")`, nil, WithFS(testFS))
+	assert.ErrorContains(t, diag, "not a regular file")
+}
+
+func TestFS_FileSet(t *testing.T) {
+	cases := map[string][]string{
+		`fileset("conf", "*.hcl")`:    {"app.hcl", "db.hcl"},
+		`fileset("conf", "**/*.hcl")`: {"app.hcl", "db.hcl", "nested/deep.hcl"},
+		`fileset(".", "*.md")`:        {"README.md"},
+		`fileset("none", "*")`:        {},
+	}
+	for code, expected := range cases {
+		t.Run(code, func(t *testing.T) {
+			v, diag := evalFS(t, code, nil, WithFS(testFS))
+			require.False(t, diag.HasErrors(), diag.Error())
+			var actual []string
+			for _, e := range v.AsValueSlice() {
+				actual = append(actual, e.AsString())
+			}
+			assert.ElementsMatch(t, expected, actual)
+		})
+	}
+}
+
+func TestFS_AbsPath(t *testing.T) {
+	v, diag := evalFS(t, `abspath("nested/../app.hcl")`, nil, WithFS(testFS), WithBaseDir("conf"))
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, "/conf/app.hcl", v.AsString())
+}
+
+func TestOS_FileFunctionsHonorBaseDir(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0700))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "a.txt"), []byte("a"), 0600))
+
+	funcs := Functions(dir)
+	v, err := funcs["file"].Call([]cty.Value{cty.StringVal("sub/a.txt")})
+	require.NoError(t, err)
+	assert.Equal(t, "a", v.AsString())
+
+	v, err = funcs["fileexists"].Call([]cty.Value{cty.StringVal("sub/a.txt")})
+	require.NoError(t, err)
+	assert.True(t, v.True())
+
+	v, err = funcs["fileset"].Call([]cty.Value{cty.StringVal("."), cty.StringVal("**/*.txt")})
+	require.NoError(t, err)
+	assert.True(t, v.Equals(cty.SetVal([]cty.Value{cty.StringVal("sub/a.txt")})).True())
+
+	v, err = funcs["filemd5"].Call([]cty.Value{cty.StringVal("sub/a.txt")})
+	require.NoError(t, err)
+	assert.Equal(t, "0cc175b9c0f1b6a831c399e269772661", v.AsString())
+}
+
+func TestFS_FileHashes(t *testing.T) {
+	cases := map[string]string{
+		`filebase64("app.hcl")`:       "YXBw",
+		`filebase64("binary.bin")`:    "//4=",
+		`filebase64sha256("app.hcl")`: "oXLO3K5HR0thXFTVEKXYSo3qMDLpWFh0MLQTU4vj8zM=",
+		`filebase64sha512("app.hcl")`: "9D95kySif735X2f64LxVszWOdZWgSXUYq64LOZimJhrv/OKa+EamJ0Gx4X4EZm1oHTH8Q8o5ODrkRQ5Zlp5UHg==",
+		`filemd5("app.hcl")`:          "d2a57dc1d883fd21fb9951699df71cc7",
+		`filesha1("app.hcl")`:         "7d1043473d55bfa90e8530d35801d4e381bc69f0",
+		`filesha256("app.hcl")`:       "a172cedcae47474b615c54d510a5d84a8dea3032e958587430b413538be3f333",
+		`filesha512("app.hcl")`:       "f43f799324a27fbdf95f67fae0bc55b3358e7595a0497518abae0b3998a6261aeffce29af846a62741b1e17e04666d681d31fc43ca39383ae4450e59969e541e",
+		`base64sha256("hello")`:       "LPJNul+wow4m6DsqxbninhsWHlwfp0JecwQzYpOLmCQ=",
+		`base64sha512("hello")`:       "m3HSJL1i83hdltRq0+o9czGb+8KJDKra4t/3JRlnPKcjI8PZm6XBHXx6zG4UuMXaDEZjR1wuXDre9G9zvN7AQw==",
+	}
+	for code, expected := range cases {
+		t.Run(code, func(t *testing.T) {
+			v, diag := evalFS(t, code, nil, WithFS(testFS), WithBaseDir("conf"))
+			require.False(t, diag.HasErrors(), diag.Error())
+			assert.Equal(t, expected, v.AsString())
+		})
+	}
+
+	_, diag := evalFS(t, `filesha256("missing.txt")`, nil, WithFS(testFS), WithBaseDir("conf"))
+	assert.True(t, diag.HasErrors())
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"github.com/hashicorp/go-cty-funcs/uuid"
 	ctyyaml "github.com/zclconf/go-cty-yaml"
-	"os"
 	"time"
 
 	"github.com/hashicorp/go-cty-funcs/cidr"
@@ -14,8 +13,6 @@ import (
 	"github.com/hashicorp/go-cty-funcs/filesystem"
 	"github.com/hashicorp/hcl/v2/ext/tryfunc"
 	"github.com/hashicorp/hcl/v2/ext/typeexpr"
-	commontpl "github.com/hashicorp/packer-plugin-sdk/template"
-	"github.com/timandy/routine"
 	"github.com/zclconf/go-cty/cty"
 	"github.com/zclconf/go-cty/cty/convert"
 	"github.com/zclconf/go-cty/cty/function"
@@ -27,165 +24,283 @@ import (
 // match for a single build.
 var InitTime time.Time
 
-var GoroutineLocalEnv = routine.NewThreadLocal[map[string]string]()
-
 func init() {
 	InitTime = time.Now().UTC()
 }
 
-func Functions(baseDir string) map[string]function.Function {
-	r := map[string]function.Function{
-		"alltrue":          AllTrueFunc,
-		"anytrue":          AnyTrueFunc,
-		"abs":              stdlib.AbsoluteFunc,
-		"abspath":          filesystem.AbsPathFunc,
-		"basename":         filesystem.BasenameFunc,
-		"base64decode":     encoding.Base64DecodeFunc,
-		"base64encode":     encoding.Base64EncodeFunc,
-		"bcrypt":           crypto.BcryptFunc,
-		"can":              tryfunc.CanFunc,
-		"ceil":             stdlib.CeilFunc,
-		"chomp":            stdlib.ChompFunc,
-		"chunklist":        stdlib.ChunklistFunc,
-		"cidrcontains":     CidrContainsFunc,
-		"cidrhost":         cidr.HostFunc,
-		"cidrnetmask":      cidr.NetmaskFunc,
-		"cidrsubnet":       cidr.SubnetFunc,
-		"cidrsubnets":      cidr.SubnetsFunc,
-		"coalesce":         collection.CoalesceFunc,
-		"coalescelist":     stdlib.CoalesceListFunc,
-		"compact":          stdlib.CompactFunc,
-		"concat":           stdlib.ConcatFunc,
-		"consul_key":       ConsulFunc,
-		"contains":         stdlib.ContainsFunc,
-		"convert":          typeexpr.ConvertFunc,
-		"csvdecode":        stdlib.CSVDecodeFunc,
-		"dirname":          filesystem.DirnameFunc,
-		"distinct":         stdlib.DistinctFunc,
-		"endswith":         EndsWithFunc,
-		"element":          stdlib.ElementFunc,
-		"file":             filesystem.MakeFileFunc(baseDir, false),
-		"fileexists":       filesystem.MakeFileExistsFunc(baseDir),
-		"fileset":          filesystem.MakeFileSetFunc(baseDir),
-		"flatten":          stdlib.FlattenFunc,
-		"floor":            stdlib.FloorFunc,
-		"format":           stdlib.FormatFunc,
-		"formatdate":       stdlib.FormatDateFunc,
-		"formatlist":       stdlib.FormatListFunc,
-		"indent":           stdlib.IndentFunc,
-		"index":            IndexFunc, // stdlib.IndexFunc is not compatible
-		"issensitive":      IsSensitiveFunc,
-		"join":             stdlib.JoinFunc,
-		"jsondecode":       stdlib.JSONDecodeFunc,
-		"jsonencode":       stdlib.JSONEncodeFunc,
-		"keys":             stdlib.KeysFunc,
-		"legacy_isotime":   LegacyIsotimeFunc,
-		"legacy_strftime":  LegacyStrftimeFunc,
-		"length":           LengthFunc,
-		"log":              stdlib.LogFunc,
-		"lookup":           stdlib.LookupFunc,
-		"lower":            stdlib.LowerFunc,
-		"matchkeys":        MatchkeysFunc,
-		"max":              stdlib.MaxFunc,
-		"md5":              crypto.Md5Func,
-		"merge":            stdlib.MergeFunc,
-		"min":              stdlib.MinFunc,
-		"nonsensitive":     NonsensitiveFunc,
-		"parseint":         stdlib.ParseIntFunc,
-		"pathexpand":       filesystem.PathExpandFunc,
-		"pow":              stdlib.PowFunc,
-		"range":            stdlib.RangeFunc,
-		"regex":            stdlib.RegexFunc,
-		"regexall":         stdlib.RegexAllFunc,
-		"regex_replace":    stdlib.RegexReplaceFunc,
-		"replace":          ReplaceFunc,
-		"reverse":          stdlib.ReverseListFunc,
-		"rsadecrypt":       crypto.RsaDecryptFunc,
-		"semvercheck":      SemverCheck,
-		"sensitive":        SensitiveFunc,
-		"setintersection":  stdlib.SetIntersectionFunc,
-		"setproduct":       stdlib.SetProductFunc,
-		"setsubtract":      stdlib.SetSubtractFunc,
-		"setunion":         stdlib.SetUnionFunc,
-		"sha1":             crypto.Sha1Func,
-		"sha256":           crypto.Sha256Func,
-		"sha512":           crypto.Sha512Func,
-		"signum":           stdlib.SignumFunc,
-		"slice":            stdlib.SliceFunc,
-		"sort":             stdlib.SortFunc,
-		"split":            stdlib.SplitFunc,
-		"startswith":       StartsWithFunc,
-		"strcontains":      StrContainsFunc,
-		"strrev":           stdlib.ReverseFunc,
-		"substr":           stdlib.SubstrFunc,
-		"sum":              SumFunc,
-		"textdecodebase64": TextDecodeBase64Func,
-		"textencodebase64": TextEncodeBase64Func,
-		"timestamp":        TimestampFunc,
-		"timeadd":          stdlib.TimeAddFunc,
-		"timecmp":          TimeCmpFunc,
-		"title":            stdlib.TitleFunc,
-		"transpose":        TransposeFunc,
-		"trim":             stdlib.TrimFunc,
-		"trimprefix":       stdlib.TrimPrefixFunc,
-		"trimspace":        stdlib.TrimSpaceFunc,
-		"trimsuffix":       stdlib.TrimSuffixFunc,
-		"try":              tryfunc.TryFunc,
-		"upper":            stdlib.UpperFunc,
-		"urlencode":        URLEncodeFunc,
-		"urldecode":        URLDecodeFunc,
-		"uuid":             UUIDFunc,
-		"uuidv4":           uuid.V4Func,
-		"uuidv5":           uuid.V5Func,
-		"values":           stdlib.ValuesFunc,
-		"vault":            VaultFunc,
-		"yamldecode":       ctyyaml.YAMLDecodeFunc,
-		"yamlencode":       ctyyaml.YAMLEncodeFunc,
-		"yaml2json":        YAML2JsonFunc,
-		"zipmap":           stdlib.ZipmapFunc,
-		"compliment":       ComplimentFunction,
-		"env":              EnvFunction,
-		"tostring":         MakeToFunc(cty.String),
-		"tonumber":         MakeToFunc(cty.Number),
-		"tobool":           MakeToFunc(cty.Bool),
-		"toset":            MakeToFunc(cty.Set(cty.DynamicPseudoType)),
-		"tolist":           MakeToFunc(cty.List(cty.DynamicPseudoType)),
-		"tomap":            MakeToFunc(cty.Map(cty.DynamicPseudoType)),
+// Category groups related functions so that they can be included in or
+// excluded from a function table together.
+type Category string
+
+const (
+	CategoryStrings     Category = "strings"
+	CategoryCollections Category = "collections"
+	CategoryNumeric     Category = "numeric"
+	CategoryEncoding    Category = "encoding"
+	CategoryConversion  Category = "conversion"
+	CategoryNetwork     Category = "network"
+	CategoryCrypto      Category = "crypto"
+	CategoryFilesystem  Category = "filesystem"
+	CategoryEnvironment Category = "environment"
+	CategorySecrets     Category = "secrets"
+	CategoryTime        Category = "time"
+)
+
+// Categories lists every category used by the built-in functions.
+var Categories = []Category{
+	CategoryStrings,
+	CategoryCollections,
+	CategoryNumeric,
+	CategoryEncoding,
+	CategoryConversion,
+	CategoryNetwork,
+	CategoryCrypto,
+	CategoryFilesystem,
+	CategoryEnvironment,
+	CategorySecrets,
+	CategoryTime,
+}
+
+type registration struct {
+	category Category
+	// impure is set for functions whose result is not determined by their
+	// arguments alone, or that have side effects.
+	impure bool
+	// upstream is where the function was copied from or is provided by,
+	// empty for functions that originate in this module.
+	upstream string
+	factory  func(c *config) function.Function
+}
+
+const (
+	upstreamStdlib     = "github.com/zclconf/go-cty/cty/function/stdlib"
+	upstreamGoCtyFuncs = "github.com/hashicorp/go-cty-funcs"
+	upstreamHCL        = "github.com/hashicorp/hcl/v2/ext"
+	upstreamCtyYAML    = "github.com/zclconf/go-cty-yaml"
+	upstreamOpenTofu   = "github.com/opentofu/opentofu/internal/lang/funcs"
+	upstreamPacker     = "github.com/hashicorp/packer/hcl2template/function"
+)
+
+func constant(f function.Function) func(c *config) function.Function {
+	return func(*config) function.Function {
+		return f
 	}
+}
+
+var registry = map[string]registration{
+	"abs":              {category: CategoryNumeric, upstream: upstreamStdlib, factory: constant(stdlib.AbsoluteFunc)},
+	"abspath":          {category: CategoryFilesystem, upstream: upstreamGoCtyFuncs, impure: true, factory: func(c *config) function.Function { return MakeAbsPathFunc(c.fsys, c.baseDir) }},
+	"alltrue":          {category: CategoryCollections, upstream: upstreamOpenTofu, factory: constant(AllTrueFunc)},
+	"anytrue":          {category: CategoryCollections, upstream: upstreamOpenTofu, factory: constant(AnyTrueFunc)},
+	"argon2id":         {category: CategoryCrypto, factory: constant(Argon2idFunc)},
+	"base64decode":     {category: CategoryEncoding, upstream: upstreamGoCtyFuncs, factory: constant(encoding.Base64DecodeFunc)},
+	"base64encode":     {category: CategoryEncoding, upstream: upstreamGoCtyFuncs, factory: constant(encoding.Base64EncodeFunc)},
+	"base64hmacsha1":   {category: CategoryCrypto, factory: constant(Base64HmacSha1Func)},
+	"base64hmacsha256": {category: CategoryCrypto, factory: constant(Base64HmacSha256Func)},
+	"base64hmacsha512": {category: CategoryCrypto, factory: constant(Base64HmacSha512Func)},
+	"base64sha256":     {category: CategoryCrypto, upstream: upstreamOpenTofu, factory: constant(Base64Sha256Func)},
+	"base64sha512":     {category: CategoryCrypto, upstream: upstreamOpenTofu, factory: constant(Base64Sha512Func)},
+	"basename":         {category: CategoryFilesystem, upstream: upstreamGoCtyFuncs, factory: constant(filesystem.BasenameFunc)},
+	"bcrypt":           {category: CategoryCrypto, upstream: upstreamGoCtyFuncs, impure: true, factory: constant(crypto.BcryptFunc)},
+	"bcryptcheck":      {category: CategoryCrypto, factory: constant(BcryptCheckFunc)},
+	"can":              {category: CategoryConversion, upstream: upstreamHCL, factory: constant(tryfunc.CanFunc)},
+	"ceil":             {category: CategoryNumeric, upstream: upstreamStdlib, factory: constant(stdlib.CeilFunc)},
+	"chomp":            {category: CategoryStrings, upstream: upstreamStdlib, factory: constant(stdlib.ChompFunc)},
+	"chunklist":        {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.ChunklistFunc)},
+	"cidrcontains":     {category: CategoryNetwork, upstream: upstreamOpenTofu, factory: constant(CidrContainsFunc)},
+	"cidrhost":         {category: CategoryNetwork, upstream: upstreamGoCtyFuncs, factory: constant(cidr.HostFunc)},
+	"cidrnetmask":      {category: CategoryNetwork, upstream: upstreamGoCtyFuncs, factory: constant(cidr.NetmaskFunc)},
+	"cidrsubnet":       {category: CategoryNetwork, upstream: upstreamGoCtyFuncs, factory: constant(cidr.SubnetFunc)},
+	"cidrsubnets":      {category: CategoryNetwork, upstream: upstreamGoCtyFuncs, factory: constant(cidr.SubnetsFunc)},
+	"coalesce":         {category: CategoryCollections, upstream: upstreamGoCtyFuncs, factory: constant(collection.CoalesceFunc)},
+	"coalescelist":     {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.CoalesceListFunc)},
+	"compact":          {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.CompactFunc)},
+	"compliment":       {category: CategoryCollections, factory: constant(ComplimentFunction)},
+	"concat":           {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.ConcatFunc)},
+	"consul_key":       {category: CategorySecrets, upstream: upstreamPacker, impure: true, factory: func(c *config) function.Function { return consulFunc(c.secrets, c.io, c.secretMarks()) }},
+	"contains":         {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.ContainsFunc)},
+	"convert":          {category: CategoryConversion, upstream: upstreamHCL, factory: constant(typeexpr.ConvertFunc)},
+	"csvdecode":        {category: CategoryEncoding, upstream: upstreamStdlib, factory: constant(stdlib.CSVDecodeFunc)},
+	"dirname":          {category: CategoryFilesystem, upstream: upstreamGoCtyFuncs, factory: constant(filesystem.DirnameFunc)},
+	"distinct":         {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.DistinctFunc)},
+	"dotenvdecode":     {category: CategoryEncoding, factory: constant(DotenvDecodeFunc)},
+	"dotenvfile":       {category: CategoryFilesystem, impure: true, factory: func(c *config) function.Function { return dotenvFileFunc(c.files()) }},
+	"element":          {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.ElementFunc)},
+	"endswith":         {category: CategoryStrings, upstream: upstreamOpenTofu, factory: constant(EndsWithFunc)},
+	"env":              {category: CategoryEnvironment, impure: true, factory: func(c *config) function.Function { return envFunc(c.envReader()) }},
+	"envexists":        {category: CategoryEnvironment, impure: true, factory: func(c *config) function.Function { return envExistsFunc(c.envReader()) }},
+	"envmap":           {category: CategoryEnvironment, impure: true, factory: func(c *config) function.Function { return envMapFunc(c.envReader()) }},
+	"envrequired":      {category: CategoryEnvironment, impure: true, factory: func(c *config) function.Function { return envRequiredFunc(c.envReader()) }},
+	"ephemeralasnull":  {category: CategoryConversion, factory: constant(EphemeralAsNullFunc)},
+	"file":             {category: CategoryFilesystem, upstream: upstreamGoCtyFuncs, impure: true, factory: func(c *config) function.Function { return fileFunc(c.files(), false) }},
+	"filebase64":       {category: CategoryFilesystem, upstream: upstreamGoCtyFuncs, impure: true, factory: func(c *config) function.Function { return fileFunc(c.files(), true) }},
+	"filebase64sha256": {category: CategoryCrypto, upstream: upstreamOpenTofu, impure: true, factory: func(c *config) function.Function { return fileBase64Sha256Func(c.files()) }},
+	"filebase64sha512": {category: CategoryCrypto, upstream: upstreamOpenTofu, impure: true, factory: func(c *config) function.Function { return fileBase64Sha512Func(c.files()) }},
+	"fileexists":       {category: CategoryFilesystem, upstream: upstreamGoCtyFuncs, impure: true, factory: func(c *config) function.Function { return fileExistsFunc(c.files()) }},
+	"filemd5":          {category: CategoryCrypto, upstream: upstreamOpenTofu, impure: true, factory: func(c *config) function.Function { return fileMd5Func(c.files()) }},
+	"fileset":          {category: CategoryFilesystem, upstream: upstreamGoCtyFuncs, impure: true, factory: func(c *config) function.Function { return fileSetFunc(c.files()) }},
+	"filesha1":         {category: CategoryCrypto, upstream: upstreamOpenTofu, impure: true, factory: func(c *config) function.Function { return fileSha1Func(c.files()) }},
+	"filesha256":       {category: CategoryCrypto, upstream: upstreamOpenTofu, impure: true, factory: func(c *config) function.Function { return fileSha256Func(c.files()) }},
+	"filesha512":       {category: CategoryCrypto, upstream: upstreamOpenTofu, impure: true, factory: func(c *config) function.Function { return fileSha512Func(c.files()) }},
+	"flatten":          {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.FlattenFunc)},
+	"floor":            {category: CategoryNumeric, upstream: upstreamStdlib, factory: constant(stdlib.FloorFunc)},
+	"format":           {category: CategoryStrings, upstream: upstreamStdlib, factory: constant(stdlib.FormatFunc)},
+	"formatdate":       {category: CategoryTime, upstream: upstreamStdlib, factory: constant(stdlib.FormatDateFunc)},
+	"formatlist":       {category: CategoryStrings, upstream: upstreamStdlib, factory: constant(stdlib.FormatListFunc)},
+	"hmacsha1":         {category: CategoryCrypto, factory: constant(HmacSha1Func)},
+	"hmacsha256":       {category: CategoryCrypto, factory: constant(HmacSha256Func)},
+	"hmacsha512":       {category: CategoryCrypto, factory: constant(HmacSha512Func)},
+	"indent":           {category: CategoryStrings, upstream: upstreamStdlib, factory: constant(stdlib.IndentFunc)},
+	"index":            {category: CategoryCollections, upstream: upstreamOpenTofu, factory: constant(IndexFunc)}, // stdlib.IndexFunc is not compatible
+	"isephemeral":      {category: CategoryConversion, factory: constant(IsEphemeralFunc)},
+	"issensitive":      {category: CategoryConversion, upstream: upstreamOpenTofu, factory: constant(IsSensitiveFunc)},
+	"join":             {category: CategoryStrings, upstream: upstreamStdlib, factory: constant(stdlib.JoinFunc)},
+	"jsondecode":       {category: CategoryEncoding, upstream: upstreamStdlib, factory: constant(stdlib.JSONDecodeFunc)},
+	"jsonencode":       {category: CategoryEncoding, upstream: upstreamStdlib, factory: constant(stdlib.JSONEncodeFunc)},
+	"keys":             {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.KeysFunc)},
+	"legacy_isotime":   {category: CategoryTime, upstream: upstreamPacker, impure: true, factory: legacyIsotimeFactory},
+	"legacy_strftime":  {category: CategoryTime, upstream: upstreamPacker, impure: true, factory: legacyStrftimeFactory},
+	"length":           {category: CategoryCollections, upstream: upstreamOpenTofu, factory: constant(LengthFunc)},
+	"log":              {category: CategoryNumeric, upstream: upstreamStdlib, factory: constant(stdlib.LogFunc)},
+	"lookup":           {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.LookupFunc)},
+	"lower":            {category: CategoryStrings, upstream: upstreamStdlib, factory: constant(stdlib.LowerFunc)},
+	"matchkeys":        {category: CategoryCollections, upstream: upstreamOpenTofu, factory: constant(MatchkeysFunc)},
+	"max":              {category: CategoryNumeric, upstream: upstreamStdlib, factory: constant(stdlib.MaxFunc)},
+	"md5":              {category: CategoryCrypto, upstream: upstreamGoCtyFuncs, factory: constant(crypto.Md5Func)},
+	"merge":            {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.MergeFunc)},
+	"min":              {category: CategoryNumeric, upstream: upstreamStdlib, factory: constant(stdlib.MinFunc)},
+	"nonsensitive":     {category: CategoryConversion, upstream: upstreamOpenTofu, factory: constant(NonsensitiveFunc)},
+	"parseint":         {category: CategoryNumeric, upstream: upstreamStdlib, factory: constant(stdlib.ParseIntFunc)},
+	"pathexpand":       {category: CategoryFilesystem, upstream: upstreamGoCtyFuncs, impure: true, factory: constant(filesystem.PathExpandFunc)},
+	"pbkdf2":           {category: CategoryCrypto, factory: constant(Pbkdf2Func)},
+	"pemdecode":        {category: CategoryCrypto, factory: constant(PEMDecodeFunc)},
+	"pow":              {category: CategoryNumeric, upstream: upstreamStdlib, factory: constant(stdlib.PowFunc)},
+	"range":            {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.RangeFunc)},
+	"regex":            {category: CategoryStrings, upstream: upstreamStdlib, factory: constant(stdlib.RegexFunc)},
+	"regex_replace":    {category: CategoryStrings, upstream: upstreamStdlib, factory: constant(stdlib.RegexReplaceFunc)},
+	"regexall":         {category: CategoryStrings, upstream: upstreamStdlib, factory: constant(stdlib.RegexAllFunc)},
+	"replace":          {category: CategoryStrings, upstream: upstreamOpenTofu, factory: constant(ReplaceFunc)},
+	"reverse":          {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.ReverseListFunc)},
+	"rsadecrypt":       {category: CategoryCrypto, upstream: upstreamGoCtyFuncs, factory: constant(crypto.RsaDecryptFunc)},
+	"scrypt":           {category: CategoryCrypto, factory: constant(ScryptFunc)},
+	"securecompare":    {category: CategoryCrypto, factory: constant(SecureCompareFunc)},
+	"semvercheck":      {category: CategoryStrings, factory: constant(SemverCheck)},
+	"sensitive":        {category: CategoryConversion, upstream: upstreamOpenTofu, factory: constant(SensitiveFunc)},
+	"sensitivepaths":   {category: CategoryConversion, factory: constant(SensitivePathsFunc)},
+	"setintersection":  {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.SetIntersectionFunc)},
+	"setproduct":       {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.SetProductFunc)},
+	"setsubtract":      {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.SetSubtractFunc)},
+	"setunion":         {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.SetUnionFunc)},
+	"sha1":             {category: CategoryCrypto, upstream: upstreamGoCtyFuncs, factory: constant(crypto.Sha1Func)},
+	"sha256":           {category: CategoryCrypto, upstream: upstreamGoCtyFuncs, factory: constant(crypto.Sha256Func)},
+	"sha512":           {category: CategoryCrypto, upstream: upstreamGoCtyFuncs, factory: constant(crypto.Sha512Func)},
+	"signum":           {category: CategoryNumeric, upstream: upstreamStdlib, factory: constant(stdlib.SignumFunc)},
+	"slice":            {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.SliceFunc)},
+	"sort":             {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.SortFunc)},
+	"split":            {category: CategoryStrings, upstream: upstreamStdlib, factory: constant(stdlib.SplitFunc)},
+	"sshfingerprint":   {category: CategoryCrypto, factory: constant(SSHFingerprintFunc)},
+	"sshpubkeydecode":  {category: CategoryCrypto, factory: constant(SSHPubKeyDecodeFunc)},
+	"startswith":       {category: CategoryStrings, upstream: upstreamOpenTofu, factory: constant(StartsWithFunc)},
+	"strcontains":      {category: CategoryStrings, upstream: upstreamOpenTofu, factory: constant(StrContainsFunc)},
+	"strrev":           {category: CategoryStrings, upstream: upstreamStdlib, factory: constant(stdlib.ReverseFunc)},
+	"substr":           {category: CategoryStrings, upstream: upstreamStdlib, factory: constant(stdlib.SubstrFunc)},
+	"sum":              {category: CategoryCollections, upstream: upstreamOpenTofu, factory: constant(SumFunc)},
+	"templatefile":     {category: CategoryFilesystem, upstream: upstreamOpenTofu, impure: true, factory: templateFileFactory},
+	"templatestring":   {category: CategoryStrings, upstream: upstreamOpenTofu, factory: templateStringFactory},
+	"textdecodebase64": {category: CategoryEncoding, upstream: upstreamOpenTofu, factory: constant(TextDecodeBase64Func)},
+	"textencodebase64": {category: CategoryEncoding, upstream: upstreamOpenTofu, factory: constant(TextEncodeBase64Func)},
+	"timeadd":          {category: CategoryTime, upstream: upstreamStdlib, factory: constant(stdlib.TimeAddFunc)},
+	"timecmp":          {category: CategoryTime, upstream: upstreamOpenTofu, factory: constant(TimeCmpFunc)},
+	"timestamp":        {category: CategoryTime, upstream: upstreamPacker, impure: true, factory: func(c *config) function.Function { return MakeTimestampFunc(c.clock) }},
+	"title":            {category: CategoryStrings, upstream: upstreamStdlib, factory: constant(stdlib.TitleFunc)},
+	"tobool":           {category: CategoryConversion, upstream: upstreamOpenTofu, factory: constant(MakeToFunc(cty.Bool))},
+	"tolist":           {category: CategoryConversion, upstream: upstreamOpenTofu, factory: constant(MakeToFunc(cty.List(cty.DynamicPseudoType)))},
+	"tomap":            {category: CategoryConversion, upstream: upstreamOpenTofu, factory: constant(MakeToFunc(cty.Map(cty.DynamicPseudoType)))},
+	"tonumber":         {category: CategoryConversion, upstream: upstreamOpenTofu, factory: constant(MakeToFunc(cty.Number))},
+	"toset":            {category: CategoryConversion, upstream: upstreamOpenTofu, factory: constant(MakeToFunc(cty.Set(cty.DynamicPseudoType)))},
+	"tostring":         {category: CategoryConversion, upstream: upstreamOpenTofu, factory: constant(MakeToFunc(cty.String))},
+	"transpose":        {category: CategoryCollections, upstream: upstreamOpenTofu, factory: constant(TransposeFunc)},
+	"trim":             {category: CategoryStrings, upstream: upstreamStdlib, factory: constant(stdlib.TrimFunc)},
+	"trimprefix":       {category: CategoryStrings, upstream: upstreamStdlib, factory: constant(stdlib.TrimPrefixFunc)},
+	"trimspace":        {category: CategoryStrings, upstream: upstreamStdlib, factory: constant(stdlib.TrimSpaceFunc)},
+	"trimsuffix":       {category: CategoryStrings, upstream: upstreamStdlib, factory: constant(stdlib.TrimSuffixFunc)},
+	"try":              {category: CategoryConversion, upstream: upstreamHCL, factory: constant(tryfunc.TryFunc)},
+	"ulid":             {category: CategoryCrypto, impure: true, factory: func(c *config) function.Function { return MakeULIDFunc(c.clock) }},
+	"upper":            {category: CategoryStrings, upstream: upstreamStdlib, factory: constant(stdlib.UpperFunc)},
+	"urldecode":        {category: CategoryEncoding, upstream: upstreamOpenTofu, factory: constant(URLDecodeFunc)},
+	"urlencode":        {category: CategoryEncoding, upstream: upstreamOpenTofu, factory: constant(URLEncodeFunc)},
+	"uuid":             {category: CategoryCrypto, upstream: upstreamOpenTofu, impure: true, factory: constant(UUIDFunc)},
+	"uuidparse":        {category: CategoryCrypto, factory: constant(UUIDParseFunc)},
+	"uuidv4":           {category: CategoryCrypto, upstream: upstreamGoCtyFuncs, impure: true, factory: constant(uuid.V4Func)},
+	"uuidv5":           {category: CategoryCrypto, upstream: upstreamGoCtyFuncs, factory: constant(uuid.V5Func)},
+	"uuidv7":           {category: CategoryCrypto, impure: true, factory: func(c *config) function.Function { return MakeUUIDv7Func(c.clock) }},
+	"uuidvalidate":     {category: CategoryCrypto, factory: constant(UUIDValidateFunc)},
+	"values":           {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.ValuesFunc)},
+	"vault":            {category: CategorySecrets, upstream: upstreamPacker, impure: true, factory: func(c *config) function.Function { return vaultFunc(c.secrets, c.io, c.secretMarks()) }},
+	"x509decode":       {category: CategoryCrypto, factory: constant(X509DecodeFunc)},
+	"yaml2json":        {category: CategoryEncoding, factory: constant(YAML2JsonFunc)},
+	"yamldecode":       {category: CategoryEncoding, upstream: upstreamCtyYAML, factory: constant(ctyyaml.YAMLDecodeFunc)},
+	"yamlencode":       {category: CategoryEncoding, upstream: upstreamCtyYAML, factory: constant(ctyyaml.YAMLEncodeFunc)},
+	"zipmap":           {category: CategoryCollections, upstream: upstreamStdlib, factory: constant(stdlib.ZipmapFunc)},
+}
+
+// Functions returns the full function table with file functions resolved
+// against baseDir. It is a shortcut for NewFunctions(WithBaseDir(baseDir)).
+func Functions(baseDir string) map[string]function.Function {
+	// Without include, exclude or extra functions the builder cannot fail.
+	r, _ := NewFunctions(WithBaseDir(baseDir))
 	return r
 }
 
-var EnvFunction = function.New(&function.Spec{
-	Description: "Read environment variable, return empty string if the variable is not set.",
-	Params: []function.Parameter{
-		{
-			Name:         "key",
-			Description:  "Environment variable name",
-			Type:         cty.String,
-			AllowUnknown: true,
-			AllowMarked:  true,
-		},
-	},
-	Type: function.StaticReturnType(cty.String),
-	RefineResult: func(builder *cty.RefinementBuilder) *cty.RefinementBuilder {
-		return builder.NotNull()
-	},
-	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
-		key := args[0]
-		if !key.IsKnown() {
-			return cty.UnknownVal(cty.String), nil
+// NewFunctions builds a function table from the given options. By default
+// every built-in function is included and file functions are resolved
+// against the current working directory.
+func NewFunctions(opts ...Option) (map[string]function.Function, error) {
+	c, err := buildTable(opts...)
+	if err != nil {
+		return nil, err
+	}
+	return c.functions, nil
+}
+
+// buildTable builds the function table from the given options and returns
+// the config holding it, for callers such as Catalog that need to know which
+// built-in function each name refers to.
+func buildTable(opts ...Option) (*config, error) {
+	c := newConfig(opts...)
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	r := make(map[string]function.Function, len(registry)+len(c.extras))
+	for name, reg := range registry {
+		if !c.selected(name, reg.category) {
+			continue
 		}
-		envKey := key.AsString()
-		localEnv := GoroutineLocalEnv.Get()
-		if localEnv != nil {
-			if env, ok := localEnv[envKey]; ok {
-				return cty.StringVal(env), nil
-			}
+		if reg.impure && c.impureMode == ImpureExclude {
+			continue
 		}
-		env := os.Getenv(envKey)
-		return cty.StringVal(env), nil
-	},
-})
+		f, ok := c.overrides[name]
+		if !ok {
+			f = reg.factory(c)
+		}
+		if reg.impure && c.impureMode == ImpureStub {
+			f = unknownStub(f)
+			c.stubbed[name] = true
+		}
+		for _, tableName := range c.tableNames(name, reg.category) {
+			r[tableName] = f
+			c.builtins[tableName] = name
+		}
+	}
+	for name, f := range c.extras {
+		if _, ok := r[name]; ok {
+			return nil, fmt.Errorf("function %q collides with a built-in function, use WithOverride to replace it", name)
+		}
+		r[name] = f
+	}
+	c.functions = r
+	return c, nil
+}
 
 func setOperationReturnType(args []cty.Value) (ret cty.Type, err error) {
 	var etys []cty.Type
@@ -247,43 +362,3 @@ func setOperationImpl(f func(s1, s2 cty.ValueSet) cty.ValueSet, allowUnknowns bo
 		return cty.SetValFromValueSet(set), nil
 	}
 }
-
-// ConsulFunc constructs a function that retrieves KV secrets from HC vault
-var ConsulFunc = function.New(&function.Spec{
-	Params: []function.Parameter{
-		{
-			Name: "key",
-			Type: cty.String,
-		},
-	},
-	Type: function.StaticReturnType(cty.String),
-	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
-		key := args[0].AsString()
-		val, err := commontpl.Consul(key)
-
-		return cty.StringVal(val), err
-	},
-})
-
-// VaultFunc constructs a function that retrieves KV secrets from HC vault
-var VaultFunc = function.New(&function.Spec{
-	Params: []function.Parameter{
-		{
-			Name: "path",
-			Type: cty.String,
-		},
-		{
-			Name: "key",
-			Type: cty.String,
-		},
-	},
-	Type: function.StaticReturnType(cty.String),
-	Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
-		path := args[0].AsString()
-		key := args[1].AsString()
-
-		val, err := commontpl.Vault(path, key)
-
-		return cty.StringVal(val), err
-	},
-})
@@ -1,6 +1,7 @@
 package hclfuncs
 
 import (
+	"fmt"
 	"reflect"
 	"strconv"
 
@@ -13,6 +14,7 @@ var TypeType = cty.Capsule("type", reflect.TypeOf(cty.Type{}))
 
 func MakeToFunc(wantTy cty.Type) function.Function {
 	return function.New(&function.Spec{
+		Description: fmt.Sprintf("Converts its argument to %s.", wantTy.FriendlyNameForConstraint()),
 		Params: []function.Parameter{
 			{
 				Name:             "v",
@@ -0,0 +1,95 @@
+package marks
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Paths returns the paths of the values within val, val itself included,
+// that have the given mark, in the order cty.Walk visits them. The path of
+// val itself is empty.
+func Paths(val cty.Value, mark valueMark) []cty.Path {
+	var paths []cty.Path
+	_, pvms := val.UnmarkDeepWithPaths()
+	for _, pvm := range pvms {
+		if _, ok := pvm.Marks[mark]; ok {
+			paths = append(paths, pvm.Path)
+		}
+	}
+	return paths
+}
+
+// StripPaths returns a copy of val where the values at the given paths no
+// longer have the given mark. Marks elsewhere, and other marks at the given
+// paths, are kept.
+func StripPaths(val cty.Value, mark valueMark, paths ...cty.Path) cty.Value {
+	unmarked, pvms := val.UnmarkDeepWithPaths()
+	kept := make([]cty.PathValueMarks, 0, len(pvms))
+	for _, pvm := range pvms {
+		if _, ok := pvm.Marks[mark]; ok && containsPath(paths, pvm.Path) {
+			m := make(cty.ValueMarks, len(pvm.Marks))
+			for k := range pvm.Marks {
+				if k != mark {
+					m[k] = struct{}{}
+				}
+			}
+			if len(m) == 0 {
+				continue
+			}
+			pvm.Marks = m
+		}
+		kept = append(kept, pvm)
+	}
+	return unmarked.MarkWithPaths(kept)
+}
+
+func containsPath(paths []cty.Path, path cty.Path) bool {
+	for _, p := range paths {
+		if p.Equals(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatPath renders path within val the way it would be written after a
+// reference in HCL, such as tags["team"] or ports[0].number. Attributes of
+// val are written without a leading dot. Set elements have no index and are
+// written [*], so that their values don't show up in the result.
+func FormatPath(val cty.Value, path cty.Path) string {
+	var b strings.Builder
+	ty := val.Type()
+	for i, step := range path {
+		switch s := step.(type) {
+		case cty.GetAttrStep:
+			if i > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(s.Name)
+			if ty.IsObjectType() && ty.HasAttribute(s.Name) {
+				ty = ty.AttributeType(s.Name)
+			}
+		case cty.IndexStep:
+			switch {
+			case ty.IsSetType():
+				b.WriteString("[*]")
+			case s.Key.Type() == cty.String:
+				b.WriteString("[" + strconv.Quote(s.Key.AsString()) + "]")
+			default:
+				b.WriteString("[" + s.Key.AsBigFloat().Text('f', -1) + "]")
+			}
+			switch {
+			case ty.IsCollectionType():
+				ty = ty.ElementType()
+			case ty.IsObjectType() && s.Key.Type() == cty.String && ty.HasAttribute(s.Key.AsString()):
+				ty = ty.AttributeType(s.Key.AsString())
+			case ty.IsTupleType() && s.Key.Type() == cty.Number:
+				idx, _ := s.Key.AsBigFloat().Int64()
+				ty = ty.TupleElementType(int(idx))
+			}
+		}
+	}
+	return b.String()
+}
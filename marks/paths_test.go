@@ -0,0 +1,44 @@
+package marks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestPaths(t *testing.T) {
+	val := cty.ObjectVal(map[string]cty.Value{
+		"name": cty.StringVal("app"),
+		"db": cty.ObjectVal(map[string]cty.Value{
+			"password": cty.StringVal("s3cr3t").Mark(Sensitive),
+			"ports":    cty.ListVal([]cty.Value{cty.NumberIntVal(5432), cty.NumberIntVal(5433).Mark(Sensitive).Mark(Ephemeral)}),
+		}),
+		"tags":  cty.MapVal(map[string]cty.Value{"team a": cty.StringVal("x").Mark(Sensitive)}),
+		"hosts": cty.SetVal([]cty.Value{cty.StringVal("secret-host").Mark(Sensitive)}),
+	}).Mark(Ephemeral)
+
+	assert.ElementsMatch(t, []string{`db.password`, `db.ports[1]`, `tags["team a"]`, `hosts`}, formatPaths(val, Sensitive))
+	// Set elements are not written, they would give the value away.
+	assert.Equal(t, "hosts[*]", FormatPath(val, cty.GetAttrPath("hosts").Index(cty.StringVal("secret-host"))))
+	assert.ElementsMatch(t, []string{``, `db.ports[1]`}, formatPaths(val, Ephemeral))
+
+	stripped := StripPaths(val, Sensitive, cty.GetAttrPath("db").GetAttr("password"), cty.GetAttrPath("db").GetAttr("ports").IndexInt(1))
+	assert.ElementsMatch(t, []string{`tags["team a"]`, `hosts`}, formatPaths(stripped, Sensitive))
+	assert.ElementsMatch(t, []string{``, `db.ports[1]`}, formatPaths(stripped, Ephemeral))
+	password, _ := cty.GetAttrPath("db").GetAttr("password").Apply(unmarked(stripped))
+	assert.Equal(t, "s3cr3t", password.AsString())
+}
+
+func unmarked(val cty.Value) cty.Value {
+	v, _ := val.UnmarkDeep()
+	return v
+}
+
+func formatPaths(val cty.Value, mark valueMark) []string {
+	var formatted []string
+	for _, p := range Paths(val, mark) {
+		formatted = append(formatted, FormatPath(val, p))
+	}
+	return formatted
+}
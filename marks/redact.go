@@ -0,0 +1,148 @@
+package marks
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclwrite"
+	"github.com/zclconf/go-cty/cty"
+	ctyjson "github.com/zclconf/go-cty/cty/json"
+)
+
+// RedactedValue is the placeholder that replaces sensitive values in
+// redacted output.
+const RedactedValue = "(sensitive value)"
+
+// Redact returns a copy of val where every sensitive value, including those
+// nested in collections, is replaced by the string RedactedValue. The
+// placeholder can't always take the type of the value it replaces, so lists
+// and sets holding sensitive values become tuples, and maps become objects.
+// Marks other than Sensitive are kept.
+func Redact(val cty.Value) cty.Value {
+	if val.HasMark(Sensitive) {
+		_, valMarks := val.Unmark()
+		delete(valMarks, Sensitive)
+		return cty.StringVal(RedactedValue).WithMarks(valMarks)
+	}
+	if !Contains(val, Sensitive) {
+		return val
+	}
+
+	val, valMarks := val.Unmark()
+	ty := val.Type()
+	switch {
+	case ty.IsListType() || ty.IsSetType() || ty.IsTupleType():
+		var elems []cty.Value
+		for it := val.ElementIterator(); it.Next(); {
+			_, elem := it.Element()
+			elems = append(elems, Redact(elem))
+		}
+		return cty.TupleVal(elems).WithMarks(valMarks)
+	case ty.IsMapType() || ty.IsObjectType():
+		attrs := make(map[string]cty.Value)
+		for it := val.ElementIterator(); it.Next(); {
+			k, elem := it.Element()
+			attrs[k.AsString()] = Redact(elem)
+		}
+		return cty.ObjectVal(attrs).WithMarks(valMarks)
+	}
+	return val.WithMarks(valMarks)
+}
+
+// RenderJSON renders val as JSON with sensitive values redacted. It fails
+// for values that are not wholly known.
+func RenderJSON(val cty.Value) ([]byte, error) {
+	val, _ = Redact(val).UnmarkDeep()
+	if !val.IsWhollyKnown() {
+		return nil, fmt.Errorf("can't render unknown values as JSON")
+	}
+	return ctyjson.Marshal(val, val.Type())
+}
+
+// RenderHCL renders val in HCL syntax with sensitive values redacted. It
+// fails for values that are not wholly known.
+func RenderHCL(val cty.Value) (string, error) {
+	val, _ = Redact(val).UnmarkDeep()
+	if !val.IsWhollyKnown() {
+		return "", fmt.Errorf("can't render unknown values as HCL")
+	}
+	return string(hclwrite.Format(hclwrite.TokensForValue(val).Bytes())), nil
+}
+
+// ScrubDiagnostics returns a copy of diags where sensitive strings are
+// replaced by RedactedValue in summaries and details. Functions may quote
+// their arguments in error messages, so the sensitive strings are found in
+// the variables that the expression of each diagnostic refers to, looked up
+// in the evaluation context of the diagnostic. Values are replaced wherever
+// they occur, which may hide harmless text that happens to match a short
+// secret.
+func ScrubDiagnostics(diags hcl.Diagnostics) hcl.Diagnostics {
+	if len(diags) == 0 {
+		return diags
+	}
+	scrubbed := make(hcl.Diagnostics, 0, len(diags))
+	for _, diag := range diags {
+		secrets := diagnosticSecrets(diag)
+		if len(secrets) == 0 {
+			scrubbed = append(scrubbed, diag)
+			continue
+		}
+		d := *diag
+		d.Summary = scrub(d.Summary, secrets)
+		d.Detail = scrub(d.Detail, secrets)
+		scrubbed = append(scrubbed, &d)
+	}
+	return scrubbed
+}
+
+func diagnosticSecrets(diag *hcl.Diagnostic) []string {
+	if diag.Expression == nil || diag.EvalContext == nil {
+		return nil
+	}
+	var secrets []string
+	for _, traversal := range diag.Expression.Variables() {
+		val, diags := traversal.TraverseAbs(diag.EvalContext)
+		if diags.HasErrors() {
+			continue
+		}
+		secrets = append(secrets, sensitiveStrings(val)...)
+	}
+	return secrets
+}
+
+// sensitiveStrings returns the known strings within val that are sensitive,
+// either marked themselves or nested in a sensitive value.
+func sensitiveStrings(val cty.Value) []string {
+	var strs []string
+	_ = cty.Walk(val, func(_ cty.Path, v cty.Value) (bool, error) {
+		if !v.HasMark(Sensitive) {
+			return true, nil
+		}
+		v, _ = v.UnmarkDeep()
+		_ = cty.Walk(v, func(_ cty.Path, v cty.Value) (bool, error) {
+			if v.Type() == cty.String && v.IsKnown() && !v.IsNull() && v.AsString() != "" {
+				strs = append(strs, v.AsString())
+			}
+			return true, nil
+		})
+		return false, nil
+	})
+	return strs
+}
+
+func scrub(s string, secrets []string) string {
+	// Replace longer secrets first, so that a secret containing another one
+	// is not left half redacted.
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	for _, secret := range secrets {
+		// Messages usually quote values with %q, which escapes them.
+		if quoted := strconv.Quote(secret); quoted[1:len(quoted)-1] != secret {
+			s = strings.ReplaceAll(s, quoted[1:len(quoted)-1], RedactedValue)
+		}
+		s = strings.ReplaceAll(s, secret, RedactedValue)
+	}
+	return s
+}
@@ -0,0 +1,77 @@
+package marks
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+func TestRedact(t *testing.T) {
+	val := cty.ObjectVal(map[string]cty.Value{
+		"name":  cty.StringVal("app"),
+		"ports": cty.ListVal([]cty.Value{cty.NumberIntVal(80), cty.NumberIntVal(443).Mark(Sensitive)}),
+		"token": cty.StringVal("s3cr3t").Mark(Sensitive),
+	})
+	expected := cty.ObjectVal(map[string]cty.Value{
+		"name":  cty.StringVal("app"),
+		"ports": cty.TupleVal([]cty.Value{cty.NumberIntVal(80), cty.StringVal(RedactedValue)}),
+		"token": cty.StringVal(RedactedValue),
+	})
+	redacted := Redact(val)
+	assert.True(t, expected.RawEquals(redacted), redacted.GoString())
+	assert.False(t, Contains(redacted, Sensitive))
+
+	plain := cty.ListVal([]cty.Value{cty.StringVal("a")})
+	assert.True(t, plain.RawEquals(Redact(plain)))
+	assert.True(t, cty.StringVal(RedactedValue).Mark(TypeType).RawEquals(Redact(cty.UnknownVal(cty.String).Mark(Sensitive).Mark(TypeType))))
+}
+
+func TestRender(t *testing.T) {
+	val := cty.ObjectVal(map[string]cty.Value{
+		"name":  cty.StringVal("app"),
+		"token": cty.StringVal("s3cr3t").Mark(Sensitive),
+	})
+	js, err := RenderJSON(val)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"name": "app", "token": "(sensitive value)"}`, string(js))
+
+	out, err := RenderHCL(val)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  name  = \"app\"\n  token = \"(sensitive value)\"\n}", out)
+
+	_, err = RenderJSON(cty.UnknownVal(cty.String))
+	assert.Error(t, err)
+	_, err = RenderHCL(cty.UnknownVal(cty.String))
+	assert.Error(t, err)
+}
+
+func TestScrubDiagnostics(t *testing.T) {
+	fail := function.New(&function.Spec{
+		Params: []function.Parameter{{Name: "v", Type: cty.String}},
+		Type:   function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			return cty.NilVal, function.NewArgErrorf(0, "invalid value %q", args[0].AsString())
+		},
+	})
+	expr, diags := hclsyntax.ParseExpression([]byte(`fail(var.token)`), "test.hcl", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	_, diags = expr.Value(&hcl.EvalContext{
+		Functions: map[string]function.Function{"fail": fail},
+		Variables: map[string]cty.Value{"var": cty.ObjectVal(map[string]cty.Value{
+			"token": cty.StringVal("s3\"cr3t").Mark(Sensitive),
+		})},
+	})
+	require.True(t, diags.HasErrors())
+	assert.Contains(t, diags.Error(), `s3\"cr3t`)
+
+	scrubbed := ScrubDiagnostics(diags)
+	assert.NotContains(t, scrubbed.Error(), "cr3t")
+	assert.Contains(t, scrubbed.Error(), `invalid value "(sensitive value)"`)
+	// The original diagnostics are left alone.
+	assert.Contains(t, diags.Error(), `s3\"cr3t`)
+}
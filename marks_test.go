@@ -0,0 +1,373 @@
+package hclfuncs
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/lonegunmanb/hclfuncs/marks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// markSamples has a valid call of every built-in function.
+// TestMarks_Propagation marks each argument of the call in turn, then every
+// element inside it, and checks that the mark reaches the result.
+var markSamples = map[string]string{
+	"abs":              `abs(-1)`,
+	"abspath":          `abspath("a.txt")`,
+	"alltrue":          `alltrue([true, true])`,
+	"anytrue":          `anytrue([false, true])`,
+	"argon2id":         `argon2id("password", "saltsalt", { memory = 64, time = 1, threads = 1 })`,
+	"base64decode":     `base64decode("aGk=")`,
+	"base64encode":     `base64encode("hi")`,
+	"base64hmacsha1":   `base64hmacsha1("key", "message")`,
+	"base64hmacsha256": `base64hmacsha256("key", "message")`,
+	"base64hmacsha512": `base64hmacsha512("key", "message")`,
+	"base64sha256":     `base64sha256("hi")`,
+	"base64sha512":     `base64sha512("hi")`,
+	"basename":         `basename("a/b.txt")`,
+	"bcrypt":           `bcrypt("hi", 4)`,
+	"bcryptcheck":      `bcryptcheck(bcrypt_hash, "hi")`,
+	"can":              `can(1)`,
+	"ceil":             `ceil(1.5)`,
+	"chomp":            `chomp("hi\n")`,
+	"chunklist":        `chunklist(["a", "b", "c"], 2)`,
+	"cidrcontains":     `cidrcontains("10.0.0.0/8", "10.1.0.0/16")`,
+	"cidrhost":         `cidrhost("10.0.0.0/24", 5)`,
+	"cidrnetmask":      `cidrnetmask("10.0.0.0/8")`,
+	"cidrsubnet":       `cidrsubnet("10.0.0.0/16", 8, 1)`,
+	"cidrsubnets":      `cidrsubnets("10.0.0.0/16", 4, 4)`,
+	"coalesce":         `coalesce("", "a")`,
+	"coalescelist":     `coalescelist([], ["a"])`,
+	"compact":          `compact(["a", "", "b"])`,
+	"compliment":       `compliment(["a", "b"], ["b"])`,
+	"concat":           `concat(["a"], ["b"])`,
+	"consul_key":       `consul_key("app/endpoint")`,
+	"contains":         `contains(["a", "b"], "b")`,
+	"convert":          `convert("1", number)`,
+	"csvdecode":        `csvdecode("a,b\n1,2\n")`,
+	"dirname":          `dirname("a/b.txt")`,
+	"distinct":         `distinct(["a", "a", "b"])`,
+	"dotenvdecode":     `dotenvdecode("A=1")`,
+	"dotenvfile":       `dotenvfile(".env")`,
+	"element":          `element(["a", "b"], 1)`,
+	"endswith":         `endswith("hello", "lo")`,
+	"env":              `env("APP_NAME", "default")`,
+	"envexists":        `envexists("APP_NAME")`,
+	"envmap":           `envmap("APP_")`,
+	"envrequired":      `envrequired("APP_NAME")`,
+	"ephemeralasnull":  `ephemeralasnull(["a"])`,
+	"file":             `file("a.txt")`,
+	"filebase64":       `filebase64("a.txt")`,
+	"filebase64sha256": `filebase64sha256("a.txt")`,
+	"filebase64sha512": `filebase64sha512("a.txt")`,
+	"fileexists":       `fileexists("a.txt")`,
+	"filemd5":          `filemd5("a.txt")`,
+	"fileset":          `fileset(".", "*.txt")`,
+	"filesha1":         `filesha1("a.txt")`,
+	"filesha256":       `filesha256("a.txt")`,
+	"filesha512":       `filesha512("a.txt")`,
+	"flatten":          `flatten([["a"], ["b"]])`,
+	"floor":            `floor(1.5)`,
+	"format":           `format("%s-%d", "a", 1)`,
+	"formatdate":       `formatdate("YYYY", "2024-01-02T03:04:05Z")`,
+	"formatlist":       `formatlist("%s-%s", ["a", "b"], "c")`,
+	"hmacsha1":         `hmacsha1("key", "message")`,
+	"hmacsha256":       `hmacsha256("key", "message")`,
+	"hmacsha512":       `hmacsha512("key", "message")`,
+	"indent":           `indent(2, "a\nb")`,
+	"index":            `index(["a", "b"], "b")`,
+	"isephemeral":      `isephemeral("a")`,
+	"issensitive":      `issensitive("a")`,
+	"join":             `join(",", ["a", "b"])`,
+	"jsondecode":       `jsondecode("{\"a\":1}")`,
+	"jsonencode":       `jsonencode({ a = 1 })`,
+	"keys":             `keys({ a = 1, b = 2 })`,
+	"legacy_isotime":   `legacy_isotime("2006")`,
+	"legacy_strftime":  `legacy_strftime("%Y")`,
+	"length":           `length(["a", "b"])`,
+	"log":              `log(8, 2)`,
+	"lookup":           `lookup({ a = "x" }, "a", "y")`,
+	"lower":            `lower("HI")`,
+	"matchkeys":        `matchkeys(["a", "b"], ["x", "y"], ["y"])`,
+	"max":              `max(1, 2)`,
+	"md5":              `md5("hi")`,
+	"merge":            `merge({ a = 1 }, { b = 2 })`,
+	"min":              `min(1, 2)`,
+	"nonsensitive":     `nonsensitive("a")`,
+	"parseint":         `parseint("ff", 16)`,
+	"pbkdf2":           `pbkdf2("password", "saltsalt", 1, 32, "sha256")`,
+	"pemdecode":        `pemdecode(certificate)`,
+	"pathexpand":       `pathexpand("a/b")`,
+	"pow":              `pow(2, 3)`,
+	"range":            `range(1, 3)`,
+	"regex":            `regex("[a-z]+", "123abc")`,
+	"regex_replace":    `regex_replace("hello", "l+", "L")`,
+	"regexall":         `regexall("[a-z]", "a1b")`,
+	"replace":          `replace("hello", "l", "L")`,
+	"reverse":          `reverse(["a", "b"])`,
+	"rsadecrypt":       `rsadecrypt(rsa_ciphertext, rsa_key)`,
+	"scrypt":           `scrypt("password", "saltsalt", { n = 16, r = 1 })`,
+	"securecompare":    `securecompare("a", "a")`,
+	"semvercheck":      `semvercheck(">= 1.0", "1.2.3")`,
+	"sensitive":        `sensitive("a")`,
+	"sensitivepaths":   `sensitivepaths("a")`,
+	"setintersection":  `setintersection(["a", "b"], ["b"])`,
+	"setproduct":       `setproduct(["a", "b"], ["c"])`,
+	"setsubtract":      `setsubtract(["a", "b"], ["b"])`,
+	"setunion":         `setunion(["a"], ["b"])`,
+	"sha1":             `sha1("hi")`,
+	"sha256":           `sha256("hi")`,
+	"sha512":           `sha512("hi")`,
+	"signum":           `signum(-2)`,
+	"slice":            `slice(["a", "b", "c"], 1, 2)`,
+	"sort":             `sort(["b", "a"])`,
+	"split":            `split(",", "a,b")`,
+	"sshfingerprint":   `sshfingerprint(ssh_key)`,
+	"sshpubkeydecode":  `sshpubkeydecode(ssh_key)`,
+	"startswith":       `startswith("hello", "he")`,
+	"strcontains":      `strcontains("hello", "ell")`,
+	"strrev":           `strrev("abc")`,
+	"substr":           `substr("hello", 1, 3)`,
+	"sum":              `sum([1, 2])`,
+	"templatefile":     `templatefile("a.tmpl", { name = "x" })`,
+	"templatestring":   `templatestring("$${name}", { name = "x" })`,
+	"textdecodebase64": `textdecodebase64("aGk=", "UTF-8")`,
+	"textencodebase64": `textencodebase64("hi", "UTF-8")`,
+	"timeadd":          `timeadd("2024-01-02T03:04:05Z", "1h")`,
+	"timecmp":          `timecmp("2024-01-02T03:04:05Z", "2024-01-02T03:04:06Z")`,
+	"timestamp":        `timestamp()`,
+	"title":            `title("hello")`,
+	"tobool":           `tobool("true")`,
+	"tolist":           `tolist(["a", "b"])`,
+	"tomap":            `tomap({ a = "x" })`,
+	"tonumber":         `tonumber("1")`,
+	"toset":            `toset(["a", "b"])`,
+	"tostring":         `tostring(1)`,
+	"transpose":        `transpose({ a = ["x", "y"] })`,
+	"trim":             `trim("?hi?", "?")`,
+	"trimprefix":       `trimprefix("hello", "he")`,
+	"trimspace":        `trimspace(" hi ")`,
+	"trimsuffix":       `trimsuffix("hello", "lo")`,
+	"ulid":             `ulid()`,
+	"try":              `try(1, 2)`,
+	"upper":            `upper("hi")`,
+	"urldecode":        `urldecode("a%20b")`,
+	"urlencode":        `urlencode("a b")`,
+	"uuid":             `uuid()`,
+	"uuidv4":           `uuidv4()`,
+	"uuidv5":           `uuidv5("dns", "example.com")`,
+	"uuidv7":           `uuidv7()`,
+	"uuidparse":        `uuidparse("0190163d-8694-739b-aea5-966c26f8ad91")`,
+	"uuidvalidate":     `uuidvalidate("0190163d-8694-739b-aea5-966c26f8ad91")`,
+	"values":           `values({ a = 1 })`,
+	"vault":            `vault("secret/data/app", "password")`,
+	"x509decode":       `x509decode(certificate)`,
+	"yaml2json":        `yaml2json("a: 1")`,
+	"yamldecode":       `yamldecode("a: 1")`,
+	"yamlencode":       `yamlencode({ a = 1 })`,
+	"zipmap":           `zipmap(["a", "b"], [1, 2])`,
+}
+
+// markExceptions lists the functions whose results don't carry the marks of
+// their arguments, on purpose.
+var markExceptions = map[string]string{
+	"nonsensitive":   "removes the sensitive mark",
+	"isephemeral":    "inspects marks, its result is not sensitive",
+	"issensitive":    "inspects marks, its result is not sensitive",
+	"sensitivepaths": "inspects marks, its result is not sensitive",
+	"can":            "takes an expression that HCL evaluates, the result only tells whether it succeeded",
+	"try":            "takes expressions that HCL evaluates, covered by TestMarks_Try",
+}
+
+// markArgExceptions lists arguments, by function name, argument index and
+// how they are marked, whose marks don't reach the result of the sample call
+// because the result doesn't depend on the marked values.
+var markArgExceptions = map[string]string{
+	"keys/0 deeply marked": "keys don't depend on the values of the map",
+	"lookup/2 marked":      "the default is not used when the key exists",
+}
+
+// markElementArgs lists arguments, by function name and argument index, whose
+// elements the function passes through to its result. When their elements
+// are marked, the marks stay on the same elements of the result instead of
+// applying to the result as a whole.
+var markElementArgs = map[string]struct{}{
+	"chunklist/0":       {},
+	"concat/0":          {},
+	"concat/1":          {},
+	"ephemeralasnull/0": {},
+	"merge/0":           {},
+	"merge/1":           {},
+	"reverse/0":         {},
+	"setproduct/0":      {},
+	"setproduct/1":      {},
+	"slice/0":           {},
+	"tolist/0":          {},
+	"tomap/0":           {},
+	"values/0":          {},
+	"zipmap/1":          {},
+}
+
+func markTestOptions() []Option {
+	return []Option{
+		WithFS(fstest.MapFS{
+			"a.txt":  {Data: []byte("a")},
+			"a.tmpl": {Data: []byte("${name}")},
+			".env":   {Data: []byte("A=1")},
+		}),
+		WithSecretBackend(MemorySecretBackend{
+			VaultSecrets: map[string]map[string]string{"secret/data/app": {"password": "s3cr3t"}},
+			ConsulKeys:   map[string]string{"app/endpoint": "https://example.com"},
+		}),
+		WithEnvProvider(MapEnvProvider{"APP_NAME": "demo"}),
+		WithClock(FixedClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))),
+	}
+}
+
+func markTestVariables(t *testing.T) map[string]cty.Value {
+	key, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	ciphertext, err := rsa.EncryptPKCS1v15(rand.Reader, &key.PublicKey, []byte("hi"))
+	require.NoError(t, err)
+	hash, err := bcrypt.GenerateFromPassword([]byte("hi"), bcrypt.MinCost)
+	require.NoError(t, err)
+	return map[string]cty.Value{
+		"bcrypt_hash":    cty.StringVal(string(hash)),
+		"certificate":    cty.StringVal(testCertificatePEM(t)),
+		"ssh_key":        cty.StringVal(testSSHPublicKey),
+		"rsa_ciphertext": cty.StringVal(base64.StdEncoding.EncodeToString(ciphertext)),
+		"rsa_key": cty.StringVal(string(pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(key),
+		}))),
+	}
+}
+
+func TestMarks_EveryFunctionHasSample(t *testing.T) {
+	for name := range registry {
+		_, ok := markSamples[name]
+		assert.True(t, ok, "no mark propagation sample for %s", name)
+	}
+}
+
+func TestMarks_Propagation(t *testing.T) {
+	funcs, err := NewFunctions(markTestOptions()...)
+	require.NoError(t, err)
+	vars := markTestVariables(t)
+
+	for name, code := range markSamples {
+		if _, ok := markExceptions[name]; ok {
+			continue
+		}
+		t.Run(name, func(t *testing.T) {
+			_, diags := evalMarkSample(code, funcs, vars)
+			require.False(t, diags.HasErrors(), "sample fails without marks: %s", diags.Error())
+
+			expr, diags := hclsyntax.ParseExpression([]byte(code), "sample.hcl", hcl.InitialPos)
+			require.False(t, diags.HasErrors(), diags.Error())
+			call, ok := expr.(*hclsyntax.FunctionCallExpr)
+			require.True(t, ok)
+			for i, argExpr := range call.Args {
+				arg, diags := argExpr.Value(&hcl.EvalContext{Functions: funcs, Variables: vars})
+				if diags.HasErrors() {
+					// Type expressions, such as the second argument of
+					// convert, are not values.
+					continue
+				}
+				// Replace the argument by a reference to its marked value.
+				rng := argExpr.Range()
+				markedCode := code[:rng.Start.Byte] + "marked" + code[rng.End.Byte:]
+				for kind, marked := range map[string]cty.Value{
+					"marked":        arg.Mark(marks.Sensitive),
+					"deeply marked": markElements(arg),
+				} {
+					if marked.RawEquals(arg) {
+						continue
+					}
+					if _, ok := markArgExceptions[fmt.Sprintf("%s/%d %s", name, i, kind)]; ok {
+						continue
+					}
+					markedVars := map[string]cty.Value{"marked": marked}
+					for k, v := range vars {
+						markedVars[k] = v
+					}
+					result, diags := evalMarkSample(markedCode, funcs, markedVars)
+					if !assert.False(t, diags.HasErrors(), "%s argument %d: %s", kind, i, diags.Error()) {
+						continue
+					}
+					if _, ok := markElementArgs[fmt.Sprintf("%s/%d", name, i)]; ok && kind == "deeply marked" {
+						assert.False(t, result.HasMark(marks.Sensitive), "%s argument %d marks the whole result %s", kind, i, result.GoString())
+						assert.NotEmpty(t, marks.Paths(result, marks.Sensitive), "%s argument %d is not marked in %s", kind, i, result.GoString())
+						continue
+					}
+					assert.True(t, result.HasMark(marks.Sensitive), "%s argument %d doesn't mark the result %s", kind, i, result.GoString())
+				}
+			}
+		})
+	}
+}
+
+func evalMarkSample(code string, funcs map[string]function.Function, vars map[string]cty.Value) (cty.Value, hcl.Diagnostics) {
+	expr, diags := hclsyntax.ParseExpression([]byte(code), "sample.hcl", hcl.InitialPos)
+	if diags.HasErrors() {
+		return cty.NilVal, diags
+	}
+	return expr.Value(&hcl.EvalContext{Functions: funcs, Variables: vars})
+}
+
+func TestMarks_Try(t *testing.T) {
+	secret := cty.StringVal("s3cr3t").Mark(marks.Sensitive)
+	funcs, err := NewFunctions()
+	require.NoError(t, err)
+	expr, diags := hclsyntax.ParseExpression([]byte(`try(secret, "fallback")`), "test.hcl", hcl.InitialPos)
+	require.False(t, diags.HasErrors())
+	v, diags := expr.Value(&hcl.EvalContext{
+		Functions: funcs,
+		Variables: map[string]cty.Value{"secret": secret},
+	})
+	require.False(t, diags.HasErrors(), diags.Error())
+	assert.True(t, v.HasMark(marks.Sensitive))
+}
+
+// markElements marks every element of a collection, or every attribute of
+// an object, and returns other values unchanged.
+func markElements(v cty.Value) cty.Value {
+	ty := v.Type()
+	if !v.IsKnown() || v.IsNull() || !(ty.IsCollectionType() || ty.IsTupleType() || ty.IsObjectType()) || v.LengthInt() == 0 {
+		return v
+	}
+	if ty.IsObjectType() || ty.IsMapType() {
+		m := v.AsValueMap()
+		for k, e := range m {
+			m[k] = e.Mark(marks.Sensitive)
+		}
+		if ty.IsMapType() {
+			return cty.MapVal(m)
+		}
+		return cty.ObjectVal(m)
+	}
+	elems := v.AsValueSlice()
+	for i, e := range elems {
+		elems[i] = e.Mark(marks.Sensitive)
+	}
+	switch {
+	case ty.IsListType():
+		return cty.ListVal(elems)
+	case ty.IsSetType():
+		return cty.SetVal(elems)
+	}
+	return cty.TupleVal(elems)
+}
@@ -0,0 +1,69 @@
+package hclfuncs
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+)
+
+// namespace is a set of built-in functions registered under a common
+// ns:: prefix.
+type namespace struct {
+	name string
+	// members lists function names or categories; empty means every
+	// function in the table.
+	members []string
+}
+
+// WithNamespace registers the given built-in functions or categories, or
+// every built-in function in the table when none is given, under the
+// namespace ns, so that upper can be called as hclfuncs::upper with
+// WithNamespace("hclfuncs"). The namespace can have several parts, like
+// provider::hclfuncs. It can be given multiple times, and a function can be
+// in several namespaces. Functions keep their flat names unless
+// WithFlatNames(false) is given.
+func WithNamespace(ns string, namesOrCategories ...string) Option {
+	return func(c *config) {
+		for _, part := range strings.Split(ns, "::") {
+			if !hclsyntax.ValidIdentifier(part) {
+				c.errs = append(c.errs, fmt.Errorf("invalid namespace %q: must be identifiers separated by ::", ns))
+				return
+			}
+		}
+		c.namespaces = append(c.namespaces, namespace{name: ns, members: namesOrCategories})
+	}
+}
+
+// WithFlatNames sets whether built-in functions are registered under their
+// flat names, like upper, in addition to their namespaced names. It is true
+// by default; set it to false to only expose the namespaces given with
+// WithNamespace.
+func WithFlatNames(enabled bool) Option {
+	return func(c *config) {
+		c.flatNames = enabled
+	}
+}
+
+// tableNames returns the names the built-in function name is registered
+// under.
+func (c *config) tableNames(name string, category Category) []string {
+	var names []string
+	if c.flatNames {
+		names = append(names, name)
+	}
+	for _, ns := range c.namespaces {
+		if len(ns.members) == 0 || matchesAny(ns.members, name, category) {
+			names = append(names, ns.name+"::"+name)
+		}
+	}
+	return names
+}
+
+// localName returns name without its namespace.
+func localName(name string) string {
+	if i := strings.LastIndex(name, "::"); i != -1 {
+		return name[i+2:]
+	}
+	return name
+}
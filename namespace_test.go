@@ -0,0 +1,90 @@
+package hclfuncs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+func TestNewFunctions_Namespace(t *testing.T) {
+	funcs, err := NewFunctions(WithNamespace("hclfuncs"), WithNamespace("net", string(CategoryNetwork)))
+	require.NoError(t, err)
+	assert.Len(t, funcs, 2*len(registry)+countCategory(CategoryNetwork))
+	assert.Contains(t, funcs, "upper")
+	assert.Contains(t, funcs, "hclfuncs::upper")
+	assert.Contains(t, funcs, "net::cidrhost")
+	assert.NotContains(t, funcs, "net::upper")
+
+	v, diag := evalFS(t, `net::cidrhost("10.0.0.0/24", 5) == hclfuncs::cidrhost("10.0.0.0/24", 5)`, nil, WithNamespace("hclfuncs"), WithNamespace("net", "cidrhost"))
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.True(t, v.True())
+}
+
+func TestNewFunctions_WithoutFlatNames(t *testing.T) {
+	funcs, err := NewFunctions(WithNamespace("provider::hclfuncs"), WithFlatNames(false), WithInclude("upper", "lower"))
+	require.NoError(t, err)
+	assert.Len(t, funcs, 2)
+	assert.Contains(t, funcs, "provider::hclfuncs::upper")
+	assert.Contains(t, funcs, "provider::hclfuncs::lower")
+}
+
+func TestNewFunctions_NamespaceAppliesOptions(t *testing.T) {
+	funcs, err := NewFunctions(
+		WithNamespace("ns"),
+		WithOverride("upper", stdlib.LowerFunc),
+		WithImpureMode(ImpureExclude),
+		WithFunction("mine::upper", stdlib.UpperFunc),
+	)
+	require.NoError(t, err)
+	v, err := funcs["ns::upper"].Call([]cty.Value{cty.StringVal("ABC")})
+	require.NoError(t, err)
+	assert.Equal(t, "abc", v.AsString())
+	assert.NotContains(t, funcs, "ns::timestamp")
+	assert.Contains(t, funcs, "mine::upper")
+
+	_, err = NewFunctions(WithNamespace("ns"), WithFunction("ns::upper", stdlib.UpperFunc))
+	assert.ErrorContains(t, err, `"ns::upper" collides`)
+}
+
+func TestNewFunctions_InvalidNamespace(t *testing.T) {
+	for _, ns := range []string{"", "1ns", "a::", "a:b"} {
+		_, err := NewFunctions(WithNamespace(ns))
+		assert.ErrorContains(t, err, "invalid namespace", ns)
+	}
+	_, err := NewFunctions(WithNamespace("ns", "not_a_function"))
+	assert.ErrorContains(t, err, `"not_a_function"`)
+}
+
+func TestNamespace_TemplateRecursion(t *testing.T) {
+	opts := []Option{WithFS(templateFS), WithBaseDir("tpl"), WithNamespace("hclfuncs")}
+	v, diag := evalFS(t, `hclfuncs::templatestring("$${hclfuncs::templatefile(\"hello.tmpl\", { name = \"ns\" })}", {})`, nil, opts...)
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, "Hello, NS!", v.AsString())
+
+	_, diag = evalFS(t, `hclfuncs::templatestring("$${hclfuncs::templatestring(tpl, { tpl = tpl })}", { tpl = "$${hclfuncs::templatestring(tpl, { tpl = tpl })}" })`, nil, opts...)
+	assert.ErrorContains(t, diag, "template string recursively renders itself")
+}
+
+func TestCatalog_Namespace(t *testing.T) {
+	c, err := Catalog(WithNamespace("net"), WithFlatNames(false), WithInclude(string(CategoryNetwork)))
+	require.NoError(t, err)
+	cidrhost, ok := c.Lookup("net::cidrhost")
+	require.True(t, ok)
+	assert.Equal(t, CategoryNetwork, cidrhost.Category)
+	assert.True(t, cidrhost.Pure)
+	_, ok = c.Lookup("cidrhost")
+	assert.False(t, ok)
+}
+
+func countCategory(category Category) int {
+	n := 0
+	for _, reg := range registry {
+		if reg.category == category {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,173 @@
+package hclfuncs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// Option configures the function table built by NewFunctions.
+type Option func(c *config)
+
+type config struct {
+	baseDir    string
+	fsys       fs.FS
+	include    []string
+	exclude    []string
+	overrides  map[string]function.Function
+	extras     map[string]function.Function
+	impureMode ImpureMode
+	secrets    SecretBackend
+	io         ioContext
+	env        EnvProvider
+	envRec     *EnvRecorder
+	clock      Clock
+	namespaces []namespace
+	flatNames  bool
+	// plainSecrets leaves the results of vault and consul_key unmarked
+	// instead of sensitive.
+	plainSecrets bool
+	// ephemeralSecrets marks the results of vault and consul_key as
+	// ephemeral.
+	ephemeralSecrets bool
+	// sensitiveEnv holds the patterns of the names of the variables whose
+	// values the env functions mark as sensitive.
+	sensitiveEnv []string
+	// buildTime reads the clock once, on the first call of a legacy time
+	// function, when a clock is configured. Otherwise it is nil and the
+	// legacy time functions fall back to InitTime.
+	buildTime func() time.Time
+	// functions is the table built by NewFunctions, for functions such as
+	// templatefile that call back into it.
+	functions map[string]function.Function
+	// builtins maps the names of built-in functions in functions, flat or
+	// namespaced, to their registry names.
+	builtins map[string]string
+	// stubbed holds the registry names of the impure functions that
+	// ImpureStub replaced with stubs.
+	stubbed map[string]bool
+	errs    []error
+}
+
+func newConfig(opts ...Option) *config {
+	c := &config{
+		baseDir:   ".",
+		secrets:   PackerSecretBackend{},
+		env:       defaultEnvProvider,
+		overrides: make(map[string]function.Function),
+		extras:    make(map[string]function.Function),
+		flatNames: true,
+		builtins:  make(map[string]string),
+		stubbed:   make(map[string]bool),
+		io:        ioContext{abandoned: new(atomic.Int64)},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.clock == nil {
+		c.clock = SystemClock
+	} else {
+		clock := c.clock
+		c.buildTime = sync.OnceValue(func() time.Time { return clock.Now().UTC() })
+	}
+	return c
+}
+
+// WithBaseDir sets the directory that relative paths given to file
+// functions are resolved against.
+func WithBaseDir(baseDir string) Option {
+	return func(c *config) {
+		c.baseDir = baseDir
+	}
+}
+
+// WithInclude restricts the table to the given function names or categories.
+// It can be given multiple times; the selections are combined.
+func WithInclude(namesOrCategories ...string) Option {
+	return func(c *config) {
+		c.include = append(c.include, namesOrCategories...)
+	}
+}
+
+// WithExclude removes the given function names or categories from the table.
+// Exclusions are applied after inclusions.
+func WithExclude(namesOrCategories ...string) Option {
+	return func(c *config) {
+		c.exclude = append(c.exclude, namesOrCategories...)
+	}
+}
+
+// WithOverride replaces the implementation of a built-in function. The name
+// must refer to a built-in function.
+func WithOverride(name string, f function.Function) Option {
+	return func(c *config) {
+		if _, ok := registry[name]; !ok {
+			c.errs = append(c.errs, fmt.Errorf("cannot override %q: no such built-in function", name))
+			return
+		}
+		c.overrides[name] = f
+	}
+}
+
+// WithFunction adds a user function to the table. The name must not collide
+// with another function in the table.
+func WithFunction(name string, f function.Function) Option {
+	return func(c *config) {
+		if _, ok := c.extras[name]; ok {
+			c.errs = append(c.errs, fmt.Errorf("function %q is added more than once", name))
+			return
+		}
+		c.extras[name] = f
+	}
+}
+
+func (c *config) table() map[string]function.Function {
+	return c.functions
+}
+
+func (c *config) validate() error {
+	errs := c.errs
+	names := append(append([]string{}, c.include...), c.exclude...)
+	for _, ns := range c.namespaces {
+		names = append(names, ns.members...)
+	}
+	for _, n := range names {
+		if !isFunctionOrCategory(n) {
+			errs = append(errs, fmt.Errorf("unknown function or category %q", n))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c *config) selected(name string, category Category) bool {
+	if len(c.include) > 0 && !matchesAny(c.include, name, category) {
+		return false
+	}
+	return !matchesAny(c.exclude, name, category)
+}
+
+func matchesAny(namesOrCategories []string, name string, category Category) bool {
+	for _, n := range namesOrCategories {
+		if n == name || Category(n) == category {
+			return true
+		}
+	}
+	return false
+}
+
+func isFunctionOrCategory(n string) bool {
+	if _, ok := registry[n]; ok {
+		return true
+	}
+	for _, c := range Categories {
+		if Category(n) == c {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package hclfuncs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/function/stdlib"
+)
+
+func TestNewFunctions_DefaultContainsAllFunctions(t *testing.T) {
+	funcs, err := NewFunctions()
+	require.NoError(t, err)
+	assert.Len(t, funcs, len(registry))
+	assert.Equal(t, len(Functions(".")), len(funcs))
+}
+
+func TestNewFunctions_IncludeByNameAndCategory(t *testing.T) {
+	funcs, err := NewFunctions(WithInclude(string(CategoryNetwork), "upper"))
+	require.NoError(t, err)
+	assert.Contains(t, funcs, "cidrsubnet")
+	assert.Contains(t, funcs, "cidrcontains")
+	assert.Contains(t, funcs, "upper")
+	assert.NotContains(t, funcs, "lower")
+	assert.NotContains(t, funcs, "vault")
+}
+
+func TestNewFunctions_Exclude(t *testing.T) {
+	funcs, err := NewFunctions(WithExclude(string(CategorySecrets), "timestamp"))
+	require.NoError(t, err)
+	assert.NotContains(t, funcs, "vault")
+	assert.NotContains(t, funcs, "consul_key")
+	assert.NotContains(t, funcs, "timestamp")
+	assert.Contains(t, funcs, "timeadd")
+}
+
+func TestNewFunctions_ExcludeWinsOverInclude(t *testing.T) {
+	funcs, err := NewFunctions(WithInclude(string(CategoryCrypto)), WithExclude("bcrypt"))
+	require.NoError(t, err)
+	assert.Contains(t, funcs, "sha256")
+	assert.NotContains(t, funcs, "bcrypt")
+}
+
+func TestNewFunctions_UnknownNameOrCategory(t *testing.T) {
+	_, err := NewFunctions(WithInclude("not_a_function"))
+	assert.ErrorContains(t, err, `"not_a_function"`)
+	_, err = NewFunctions(WithExclude("not_a_category"))
+	assert.ErrorContains(t, err, `"not_a_category"`)
+}
+
+func TestNewFunctions_Override(t *testing.T) {
+	funcs, err := NewFunctions(WithOverride("upper", stdlib.LowerFunc))
+	require.NoError(t, err)
+	v, err := funcs["upper"].Call([]cty.Value{cty.StringVal("ABC")})
+	require.NoError(t, err)
+	assert.Equal(t, "abc", v.AsString())
+
+	_, err = NewFunctions(WithOverride("not_a_function", stdlib.LowerFunc))
+	assert.Error(t, err)
+}
+
+func TestNewFunctions_ExtraFunctions(t *testing.T) {
+	hello := function.New(&function.Spec{
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			return cty.StringVal("hello"), nil
+		},
+	})
+	funcs, err := NewFunctions(WithFunction("hello", hello))
+	require.NoError(t, err)
+	assert.Contains(t, funcs, "hello")
+
+	_, err = NewFunctions(WithFunction("upper", hello))
+	assert.ErrorContains(t, err, `"upper"`)
+
+	_, err = NewFunctions(WithFunction("hello", hello), WithFunction("hello", hello))
+	assert.Error(t, err)
+
+	funcs, err = NewFunctions(WithExclude("upper"), WithFunction("upper", hello))
+	require.NoError(t, err)
+	v, err := funcs["upper"].Call(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", v.AsString())
+}
@@ -0,0 +1,299 @@
+package hclfuncs
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"hash"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"github.com/zclconf/go-cty/cty/gocty"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+)
+
+// maxDerivedKeyLength caps the length of the keys derived by argon2id,
+// scrypt and pbkdf2, and maxDerivationMemory the memory, in bytes, that
+// argon2id and scrypt may use, so that a configuration can't exhaust the
+// memory of the process evaluating it. Likewise, the work of each function
+// is capped so that a single call takes about a second at most:
+// maxArgon2idWork caps time * memory, in KiB, maxScryptWork caps n * r * p,
+// and maxPbkdf2Work caps the iterations times the number of hash blocks in
+// the key. maxBcryptCost caps the cost of the hashes that bcryptcheck
+// accepts; each step doubles the work, and cost 16 already takes seconds.
+const (
+	maxDerivedKeyLength = 1024
+	maxDerivationMemory = 1 << 30
+	maxArgon2idWork     = 1 << 20
+	maxScryptWork       = 1 << 21
+	maxPbkdf2Work       = 1 << 21
+	maxBcryptCost       = 16
+)
+
+// BcryptCheckFunc constructs a function that checks a password against a
+// bcrypt hash, as produced by bcrypt.
+var BcryptCheckFunc = function.New(&function.Spec{
+	Description: "Returns `true` if the given password matches the given bcrypt hash. Hashes with a cost above 16 are rejected.",
+	Params: []function.Parameter{
+		{
+			Name:        "hash",
+			Description: "The bcrypt hash, such as one returned by bcrypt",
+			Type:        cty.String,
+		},
+		{
+			Name:        "password",
+			Description: "The password to check",
+			Type:        cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(cty.Bool),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		hash := []byte(args[0].AsString())
+		cost, err := bcrypt.Cost(hash)
+		if err != nil {
+			return cty.UnknownVal(cty.Bool), function.NewArgErrorf(0, "invalid bcrypt hash: %s", err)
+		}
+		if cost > maxBcryptCost {
+			return cty.UnknownVal(cty.Bool), function.NewArgErrorf(0, "bcrypt cost %d is above the maximum of %d", cost, maxBcryptCost)
+		}
+		err = bcrypt.CompareHashAndPassword(hash, []byte(args[1].AsString()))
+		switch {
+		case err == nil:
+			return cty.True, nil
+		case errors.Is(err, bcrypt.ErrMismatchedHashAndPassword):
+			return cty.False, nil
+		}
+		return cty.UnknownVal(cty.Bool), function.NewArgErrorf(0, "invalid bcrypt hash: %s", err)
+	},
+})
+
+// argon2idParams are the parameters of argon2id. Omitted attributes take the
+// values recommended by RFC 9106 for memory constrained environments.
+var argon2idParams = cty.ObjectWithOptionalAttrs(map[string]cty.Type{
+	"time":    cty.Number,
+	"memory":  cty.Number,
+	"threads": cty.Number,
+	"keylen":  cty.Number,
+}, []string{"time", "memory", "threads", "keylen"})
+
+// Argon2idFunc constructs a function that derives a key from a password and
+// a salt with Argon2id, and encodes it with hexadecimal digits.
+var Argon2idFunc = function.New(&function.Spec{
+	Description: "Derives a key from a password and a salt with Argon2id and encodes it with hexadecimal digits. `params` may set `time` (iterations, 3 by default), `memory` (in KiB, 65536 by default), `threads` (4 by default) and `keylen` (in bytes, 32 by default).",
+	Params: []function.Parameter{
+		{
+			Name:        "password",
+			Description: "The password",
+			Type:        cty.String,
+		},
+		{
+			Name:        "salt",
+			Description: "The salt, at least 8 bytes long",
+			Type:        cty.String,
+		},
+		{
+			Name:        "params",
+			Description: "The cost parameters",
+			Type:        argon2idParams,
+		},
+	},
+	Type:         function.StaticReturnType(cty.String),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		salt, err := saltArg(args[1], 1)
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		params, err := intParams(args[2], 2, map[string]paramRange{
+			"time":    {def: 3, min: 1, max: 1 << 16},
+			"memory":  {def: 64 * 1024, min: 8, max: maxDerivationMemory / 1024},
+			"threads": {def: 4, min: 1, max: 255},
+			"keylen":  {def: 32, min: 4, max: maxDerivedKeyLength},
+		})
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		if params["memory"] < 8*params["threads"] {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(2, "memory must be at least 8 KiB per thread")
+		}
+		if params["time"]*params["memory"] > maxArgon2idWork {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(2, "time * memory must be at most %d KiB", maxArgon2idWork)
+		}
+		key := argon2.IDKey([]byte(args[0].AsString()), salt, uint32(params["time"]), uint32(params["memory"]), uint8(params["threads"]), uint32(params["keylen"]))
+		return cty.StringVal(hex.EncodeToString(key)), nil
+	},
+})
+
+// scryptParams are the parameters of scrypt.
+var scryptParams = cty.ObjectWithOptionalAttrs(map[string]cty.Type{
+	"n":      cty.Number,
+	"r":      cty.Number,
+	"p":      cty.Number,
+	"keylen": cty.Number,
+}, []string{"n", "r", "p", "keylen"})
+
+// ScryptFunc constructs a function that derives a key from a password and a
+// salt with scrypt, and encodes it with hexadecimal digits.
+var ScryptFunc = function.New(&function.Spec{
+	Description: "Derives a key from a password and a salt with scrypt and encodes it with hexadecimal digits. `params` may set `n` (the CPU and memory cost, a power of two, 32768 by default), `r` (the block size, 8 by default), `p` (the parallelization, 1 by default) and `keylen` (in bytes, 32 by default).",
+	Params: []function.Parameter{
+		{
+			Name:        "password",
+			Description: "The password",
+			Type:        cty.String,
+		},
+		{
+			Name:        "salt",
+			Description: "The salt, at least 8 bytes long",
+			Type:        cty.String,
+		},
+		{
+			Name:        "params",
+			Description: "The cost parameters",
+			Type:        scryptParams,
+		},
+	},
+	Type:         function.StaticReturnType(cty.String),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		salt, err := saltArg(args[1], 1)
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		params, err := intParams(args[2], 2, map[string]paramRange{
+			"n":      {def: 32768, min: 2, max: 1 << 22},
+			"r":      {def: 8, min: 1, max: 64},
+			"p":      {def: 1, min: 1, max: 64},
+			"keylen": {def: 32, min: 4, max: maxDerivedKeyLength},
+		})
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		if 128*params["n"]*params["r"] > maxDerivationMemory {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(2, "n and r would use more than %d MiB", maxDerivationMemory>>20)
+		}
+		if params["n"]*params["r"]*params["p"] > maxScryptWork {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(2, "n * r * p must be at most %d", maxScryptWork)
+		}
+		key, err := scrypt.Key([]byte(args[0].AsString()), salt, params["n"], params["r"], params["p"], params["keylen"])
+		if err != nil {
+			return cty.UnknownVal(cty.String), function.NewArgError(2, err)
+		}
+		return cty.StringVal(hex.EncodeToString(key)), nil
+	},
+})
+
+// pbkdf2Hashes are the hash functions that pbkdf2 supports.
+var pbkdf2Hashes = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+// Pbkdf2Func constructs a function that derives a key from a password and a
+// salt with PBKDF2, and encodes it with hexadecimal digits.
+var Pbkdf2Func = function.New(&function.Spec{
+	Description: "Derives a key from a password and a salt with PBKDF2 and encodes it with hexadecimal digits.",
+	Params: []function.Parameter{
+		{
+			Name:        "password",
+			Description: "The password",
+			Type:        cty.String,
+		},
+		{
+			Name:        "salt",
+			Description: "The salt, at least 8 bytes long",
+			Type:        cty.String,
+		},
+		{
+			Name:        "iterations",
+			Description: "The number of iterations",
+			Type:        cty.Number,
+		},
+		{
+			Name:        "keylen",
+			Description: "The length of the key, in bytes",
+			Type:        cty.Number,
+		},
+		{
+			Name:        "hash",
+			Description: "The hash function: sha1, sha256 or sha512",
+			Type:        cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(cty.String),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		salt, err := saltArg(args[1], 1)
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		iterations, err := intArg(args[2], 2, "iterations", paramRange{min: 1, max: 1 << 24})
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		keylen, err := intArg(args[3], 3, "keylen", paramRange{min: 4, max: maxDerivedKeyLength})
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		hf, ok := pbkdf2Hashes[args[4].AsString()]
+		if !ok {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(4, "unsupported hash %q, must be sha1, sha256 or sha512", args[4].AsString())
+		}
+		blocks := (keylen + hf().Size() - 1) / hf().Size()
+		if iterations*blocks > maxPbkdf2Work {
+			return cty.UnknownVal(cty.String), function.NewArgErrorf(2, "iterations * ceil(keylen / hash size) must be at most %d", maxPbkdf2Work)
+		}
+		key := pbkdf2.Key([]byte(args[0].AsString()), salt, iterations, keylen, hf)
+		return cty.StringVal(hex.EncodeToString(key)), nil
+	},
+})
+
+func saltArg(v cty.Value, argIdx int) ([]byte, error) {
+	salt := []byte(v.AsString())
+	if len(salt) < 8 {
+		return nil, function.NewArgErrorf(argIdx, "salt must be at least 8 bytes long")
+	}
+	return salt, nil
+}
+
+// paramRange is the default value and the bounds of an integer parameter.
+type paramRange struct {
+	def, min, max int
+}
+
+// intParams reads the integer attributes of the object params, using their
+// defaults for null attributes.
+func intParams(params cty.Value, argIdx int, ranges map[string]paramRange) (map[string]int, error) {
+	r := make(map[string]int, len(ranges))
+	for name, rng := range ranges {
+		attr := params.GetAttr(name)
+		if attr.IsNull() {
+			r[name] = rng.def
+			continue
+		}
+		v, err := intArg(attr, argIdx, name, rng)
+		if err != nil {
+			return nil, err
+		}
+		r[name] = v
+	}
+	return r, nil
+}
+
+func intArg(v cty.Value, argIdx int, name string, rng paramRange) (int, error) {
+	var i int
+	if err := gocty.FromCtyValue(v, &i); err != nil {
+		return 0, function.NewArgErrorf(argIdx, "%s must be a whole number", name)
+	}
+	if i < rng.min || i > rng.max {
+		return 0, function.NewArgErrorf(argIdx, "%s must be between %d and %d", name, rng.min, rng.max)
+	}
+	return i, nil
+}
@@ -0,0 +1,97 @@
+package hclfuncs
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+func TestBcryptCheck(t *testing.T) {
+	hash, diag := evalFS(t, `bcrypt("s3cr3t", 4)`, nil)
+	require.False(t, diag.HasErrors(), diag.Error())
+
+	for password, expected := range map[string]bool{"s3cr3t": true, "other": false} {
+		v, err := BcryptCheckFunc.Call([]cty.Value{hash, cty.StringVal(password)})
+		require.NoError(t, err)
+		assert.Equal(t, expected, v.True(), password)
+	}
+
+	_, err := BcryptCheckFunc.Call([]cty.Value{cty.StringVal("not a hash"), cty.StringVal("s3cr3t")})
+	assert.ErrorContains(t, err, "invalid bcrypt hash")
+
+	costly := strings.Replace(hash.AsString(), "$04$", "$17$", 1)
+	_, err = BcryptCheckFunc.Call([]cty.Value{cty.StringVal(costly), cty.StringVal("s3cr3t")})
+	var argErr function.ArgError
+	require.ErrorAs(t, err, &argErr)
+	assert.Equal(t, 0, argErr.Index)
+	assert.ErrorContains(t, err, "bcrypt cost 17 is above the maximum of 16")
+}
+
+func TestKeyDerivationFunctions(t *testing.T) {
+	cases := map[string]string{
+		// From the test vectors of golang.org/x/crypto/argon2.
+		`argon2id("password", "somesalt", { time = 1, memory = 64, threads = 1, keylen = 24 })`: "655ad15eac652dc59f7170a7332bf49b8469be1fdb9c28bb",
+		`scrypt("password", "saltsalt", { n = 1024 })`:                                          "00e2d710448270f99fd83c54dc3e3b649c69e594dc1c2d12d8c6f67855dce2d2",
+		`pbkdf2("password", "saltsalt", 1000, 32, "sha256")`:                                    "135f7a66144fcf0fb003ce048f31f024ed5cbff30525d3ba0bfb3199479362a6",
+		`pbkdf2("password", "saltsalt", 1000, 20, "sha1")`:                                      "e9febff54bfce668fde301acc85563cc9dc71ef6",
+	}
+	for code, expected := range cases {
+		t.Run(code, func(t *testing.T) {
+			v, diag := evalFS(t, code, nil)
+			require.False(t, diag.HasErrors(), diag.Error())
+			assert.Equal(t, expected, v.AsString())
+		})
+	}
+
+	v, diag := evalFS(t, `length(argon2id("password", "saltsalt", { memory = 1024 }))`, nil)
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.True(t, v.RawEquals(cty.NumberIntVal(64)), v.GoString())
+
+	errs := map[string]string{
+		`argon2id("password", "salt", {})`:                              "salt must be at least 8 bytes long",
+		`argon2id("password", "saltsalt", { threads = 0 })`:             "threads must be between 1 and 255",
+		`argon2id("password", "saltsalt", { memory = 8, threads = 2 })`: "memory must be at least 8 KiB per thread",
+		`argon2id("password", "saltsalt", { time = 1.5 })`:              "time must be a whole number",
+		`scrypt("password", "saltsalt", { n = 1000 })`:                  "scrypt: N must be > 1 and a power of 2",
+		`scrypt("password", "saltsalt", { n = 4194304, r = 8 })`:        "n and r would use more than 1024 MiB",
+		`pbkdf2("password", "saltsalt", 1, 32, "md5")`:                  `unsupported hash "md5"`,
+		`pbkdf2("password", "saltsalt", 0, 32, "sha1")`:                 "iterations must be between 1 and 16777216",
+	}
+	for code, msg := range errs {
+		t.Run(code, func(t *testing.T) {
+			_, diag := evalFS(t, code, nil)
+			assert.ErrorContains(t, diag, msg)
+		})
+	}
+}
+
+func TestKeyDerivationWorkLimits(t *testing.T) {
+	accepted := []string{
+		`argon2id("password", "saltsalt", { time = 1024, memory = 1024 })`,
+		`scrypt("password", "saltsalt", { n = 16384, r = 2, p = 64 })`,
+		`pbkdf2("password", "saltsalt", 1048576, 40, "sha1")`,
+	}
+	for _, code := range accepted {
+		t.Run(code, func(t *testing.T) {
+			_, diag := evalFS(t, code, nil)
+			require.False(t, diag.HasErrors(), diag.Error())
+		})
+	}
+
+	rejected := map[string]string{
+		`argon2id("password", "saltsalt", { time = 1025, memory = 1024 })`: "time * memory must be at most 1048576 KiB",
+		`scrypt("password", "saltsalt", { n = 16384, r = 5, p = 26 })`:     "n * r * p must be at most 2097152",
+		`pbkdf2("password", "saltsalt", 1048576, 41, "sha1")`:              "iterations * ceil(keylen / hash size) must be at most 2097152",
+		`pbkdf2("password", "saltsalt", 2097153, 20, "sha1")`:              "iterations * ceil(keylen / hash size) must be at most 2097152",
+	}
+	for code, msg := range rejected {
+		t.Run(code, func(t *testing.T) {
+			_, diag := evalFS(t, code, nil)
+			assert.ErrorContains(t, diag, msg)
+		})
+	}
+}
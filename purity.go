@@ -0,0 +1,66 @@
+package hclfuncs
+
+import (
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// ImpureMode controls how NewFunctions handles impure functions, i.e.
+// functions such as timestamp, uuid, env or vault whose results are not
+// determined by their arguments alone, or that have side effects.
+type ImpureMode int
+
+const (
+	// ImpureAllow registers impure functions as usual.
+	ImpureAllow ImpureMode = iota
+	// ImpureExclude leaves impure functions out of the table, so that any
+	// call to them is reported as a call to an unknown function.
+	ImpureExclude
+	// ImpureStub replaces impure functions with stubs that accept the same
+	// arguments but always return an unknown value of the same type.
+	ImpureStub
+)
+
+// WithImpureMode sets how impure functions are handled. It applies to
+// built-in functions, including overridden ones; functions added with
+// WithFunction are always registered as given.
+func WithImpureMode(mode ImpureMode) Option {
+	return func(c *config) {
+		c.impureMode = mode
+	}
+}
+
+// IsPure reports whether the built-in function with the given name always
+// returns the same result for the same arguments without side effects. It
+// returns false for names that are not built-in functions.
+func IsPure(name string) bool {
+	reg, ok := registry[name]
+	return ok && !reg.impure
+}
+
+// unknownStub returns a function with the same signature as f whose result
+// is always unknown, carrying over any marks found on the arguments.
+func unknownStub(f function.Function) function.Function {
+	return function.New(&function.Spec{
+		Description: f.Description(),
+		Params:      f.Params(),
+		VarParam:    f.VarParam(),
+		Type: func(args []cty.Value) (cty.Type, error) {
+			// Type checking with unknown arguments keeps functions that
+			// inspect values, like templatefile, from doing any work.
+			unknowns := make([]cty.Value, len(args))
+			for i, arg := range args {
+				unknowns[i] = cty.UnknownVal(arg.Type())
+			}
+			return f.ReturnTypeForValues(unknowns)
+		},
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			var argMarks []cty.ValueMarks
+			for _, arg := range args {
+				_, m := arg.UnmarkDeep()
+				argMarks = append(argMarks, m)
+			}
+			return cty.UnknownVal(retType).WithMarks(argMarks...), nil
+		},
+	})
+}
@@ -0,0 +1,65 @@
+package hclfuncs
+
+import (
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/lonegunmanb/hclfuncs/marks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestIsPure(t *testing.T) {
+	for _, name := range []string{"timestamp", "uuid", "uuidv4", "env", "vault", "consul_key", "file", "fileset", "bcrypt"} {
+		assert.False(t, IsPure(name), name)
+	}
+	for _, name := range []string{"upper", "sha256", "uuidv5", "cidrsubnet", "timeadd"} {
+		assert.True(t, IsPure(name), name)
+	}
+	assert.False(t, IsPure("not_a_function"))
+}
+
+func TestNewFunctions_ImpureExclude(t *testing.T) {
+	funcs, err := NewFunctions(WithImpureMode(ImpureExclude))
+	require.NoError(t, err)
+	for name := range registry {
+		_, ok := funcs[name]
+		assert.Equal(t, IsPure(name), ok, name)
+	}
+}
+
+func TestNewFunctions_ImpureStub(t *testing.T) {
+	funcs, err := NewFunctions(WithImpureMode(ImpureStub))
+	require.NoError(t, err)
+	assert.Len(t, funcs, len(registry))
+
+	cases := map[string]cty.Type{
+		`timestamp()`:              cty.String,
+		`uuid()`:                   cty.String,
+		`env("HOME")`:              cty.String,
+		`vault("secret/foo", "k")`: cty.String,
+		`fileset(".", "*.go")`:     cty.Set(cty.String),
+		`upper(timestamp())`:       cty.String,
+	}
+	for code, ty := range cases {
+		t.Run(code, func(t *testing.T) {
+			exp, diag := hclsyntax.ParseExpression([]byte(code), "test.hcl", hcl.InitialPos)
+			require.False(t, diag.HasErrors())
+			value, diag := exp.Value(&hcl.EvalContext{Functions: funcs})
+			require.False(t, diag.HasErrors(), diag.Error())
+			assert.False(t, value.IsKnown())
+			assert.True(t, value.Type().Equals(ty))
+		})
+	}
+}
+
+func TestNewFunctions_ImpureStubKeepsArgumentMarks(t *testing.T) {
+	funcs, err := NewFunctions(WithImpureMode(ImpureStub))
+	require.NoError(t, err)
+	v, err := funcs["env"].Call([]cty.Value{cty.StringVal("HOME").Mark(marks.Sensitive)})
+	require.NoError(t, err)
+	assert.False(t, v.IsKnown())
+	assert.True(t, v.HasMark(marks.Sensitive))
+}
@@ -0,0 +1,219 @@
+package hclfuncs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	commontpl "github.com/hashicorp/packer-plugin-sdk/template"
+	"github.com/lonegunmanb/hclfuncs/marks"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// SecretBackend is the source of secrets read by the vault and consul_key
+// functions. Implementations should give up when ctx is done; the functions
+// stop waiting for them at that point anyway.
+type SecretBackend interface {
+	// Vault returns the value of key in the Vault secret at path.
+	Vault(ctx context.Context, path, key string) (string, error)
+	// Consul returns the value stored at key in the Consul KV store.
+	Consul(ctx context.Context, key string) (string, error)
+}
+
+// PackerSecretBackend reads secrets with the Packer plugin SDK, which takes
+// its connection settings from the process environment (VAULT_ADDR,
+// CONSUL_HTTP_ADDR and friends). It is the default SecretBackend. The SDK
+// takes no context, so a request that was started keeps running after ctx is
+// done, only its result is dropped.
+type PackerSecretBackend struct{}
+
+func (PackerSecretBackend) Vault(ctx context.Context, path, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return commontpl.Vault(path, key)
+}
+
+func (PackerSecretBackend) Consul(ctx context.Context, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	return commontpl.Consul(key)
+}
+
+// MemorySecretBackend serves secrets from memory, it's meant for tests.
+type MemorySecretBackend struct {
+	// VaultSecrets maps a secret path to the key/value pairs of the secret.
+	VaultSecrets map[string]map[string]string `json:"vault"`
+	// ConsulKeys maps a Consul key to its value.
+	ConsulKeys map[string]string `json:"consul"`
+}
+
+func (b MemorySecretBackend) Vault(ctx context.Context, path, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	secret, ok := b.VaultSecrets[path]
+	if !ok {
+		return "", fmt.Errorf("vault path does not exist: %s", path)
+	}
+	val, ok := secret[key]
+	if !ok {
+		return "", fmt.Errorf("vault data was empty at the given path %s for key %s", path, key)
+	}
+	return val, nil
+}
+
+func (b MemorySecretBackend) Consul(ctx context.Context, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	val, ok := b.ConsulKeys[key]
+	if !ok {
+		return "", fmt.Errorf("key does not exist in consul: %s", key)
+	}
+	return val, nil
+}
+
+// FileSecretBackend serves secrets from a JSON file, it's meant for tests
+// and offline runs. The file is read on every call and has the shape:
+//
+//	{
+//	  "vault":  {"secret/data/app": {"password": "..."}},
+//	  "consul": {"app/endpoint": "..."}
+//	}
+type FileSecretBackend struct {
+	Path string
+}
+
+func (b FileSecretBackend) Vault(ctx context.Context, path, key string) (string, error) {
+	m, err := b.load()
+	if err != nil {
+		return "", err
+	}
+	return m.Vault(ctx, path, key)
+}
+
+func (b FileSecretBackend) Consul(ctx context.Context, key string) (string, error) {
+	m, err := b.load()
+	if err != nil {
+		return "", err
+	}
+	return m.Consul(ctx, key)
+}
+
+func (b FileSecretBackend) load() (MemorySecretBackend, error) {
+	var m MemorySecretBackend
+	content, err := os.ReadFile(b.Path)
+	if err != nil {
+		return m, fmt.Errorf("failed to read secret file %s: %w", b.Path, err)
+	}
+	if err = json.Unmarshal(content, &m); err != nil {
+		return m, fmt.Errorf("failed to parse secret file %s: %w", b.Path, err)
+	}
+	return m, nil
+}
+
+// WithSecretBackend sets the backend that vault and consul_key read from.
+// PackerSecretBackend is used when this option is not given.
+func WithSecretBackend(backend SecretBackend) Option {
+	return func(c *config) {
+		c.secrets = backend
+	}
+}
+
+// WithSensitiveSecrets sets whether vault and consul_key mark the values
+// they return with marks.Sensitive. They do by default.
+func WithSensitiveSecrets(enabled bool) Option {
+	return func(c *config) {
+		c.plainSecrets = !enabled
+	}
+}
+
+// WithEphemeralSecrets marks the results of vault and consul_key with
+// marks.Ephemeral when enabled, so that callers can refuse to persist them.
+func WithEphemeralSecrets(enabled bool) Option {
+	return func(c *config) {
+		c.ephemeralSecrets = enabled
+	}
+}
+
+// secretMarks returns the marks of the values read by vault and consul_key.
+func (c *config) secretMarks() cty.ValueMarks {
+	m := make(cty.ValueMarks)
+	if !c.plainSecrets {
+		m[marks.Sensitive] = struct{}{}
+	}
+	if c.ephemeralSecrets {
+		m[marks.Ephemeral] = struct{}{}
+	}
+	return m
+}
+
+// ConsulFunc constructs a function that retrieves KV secrets from HC vault
+var ConsulFunc = MakeConsulFunc(PackerSecretBackend{})
+
+// MakeConsulFunc constructs a consul_key function that reads from backend.
+// Its results are marked as sensitive.
+func MakeConsulFunc(backend SecretBackend) function.Function {
+	return consulFunc(backend, ioContext{}, cty.NewValueMarks(marks.Sensitive))
+}
+
+func consulFunc(backend SecretBackend, io ioContext, resultMarks cty.ValueMarks) function.Function {
+	return function.New(&function.Spec{
+		Description: "Reads the value stored at the given key in the Consul KV store.",
+		Params: []function.Parameter{
+			{
+				Name: "key",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			key := args[0].AsString()
+			val, err := ioRun(io, "reading consul key "+key, func(ctx context.Context) (string, error) {
+				return backend.Consul(ctx, key)
+			})
+
+			return cty.StringVal(val).WithMarks(resultMarks), err
+		},
+	})
+}
+
+// VaultFunc constructs a function that retrieves KV secrets from HC vault
+var VaultFunc = MakeVaultFunc(PackerSecretBackend{})
+
+// MakeVaultFunc constructs a vault function that reads from backend. Its
+// results are marked as sensitive.
+func MakeVaultFunc(backend SecretBackend) function.Function {
+	return vaultFunc(backend, ioContext{}, cty.NewValueMarks(marks.Sensitive))
+}
+
+func vaultFunc(backend SecretBackend, io ioContext, resultMarks cty.ValueMarks) function.Function {
+	return function.New(&function.Spec{
+		Description: "Reads the value of a key in the Vault secret at the given path.",
+		Params: []function.Parameter{
+			{
+				Name: "path",
+				Type: cty.String,
+			},
+			{
+				Name: "key",
+				Type: cty.String,
+			},
+		},
+		Type: function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			path := args[0].AsString()
+			key := args[1].AsString()
+
+			val, err := ioRun(io, "reading vault secret "+path, func(ctx context.Context) (string, error) {
+				return backend.Vault(ctx, path, key)
+			})
+
+			return cty.StringVal(val).WithMarks(resultMarks), err
+		},
+	})
+}
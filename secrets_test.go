@@ -0,0 +1,103 @@
+package hclfuncs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/lonegunmanb/hclfuncs/marks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func evalWithSecrets(t *testing.T, backend SecretBackend, code string) (string, hcl.Diagnostics) {
+	funcs, err := NewFunctions(WithSecretBackend(backend))
+	require.NoError(t, err)
+	exp, diag := hclsyntax.ParseExpression([]byte(code), "test.hcl", hcl.InitialPos)
+	require.False(t, diag.HasErrors())
+	value, diag := exp.Value(&hcl.EvalContext{Functions: funcs})
+	if diag.HasErrors() {
+		return "", diag
+	}
+	value, _ = value.Unmark()
+	return value.AsString(), nil
+}
+
+func TestSecretBackend_Memory(t *testing.T) {
+	backend := MemorySecretBackend{
+		VaultSecrets: map[string]map[string]string{
+			"secret/data/app": {"password": "s3cr3t"},
+		},
+		ConsulKeys: map[string]string{
+			"app/endpoint": "https://example.com",
+		},
+	}
+	v, diag := evalWithSecrets(t, backend, `vault("secret/data/app", "password")`)
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, "s3cr3t", v)
+
+	v, diag = evalWithSecrets(t, backend, `consul_key("app/endpoint")`)
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, "https://example.com", v)
+
+	_, diag = evalWithSecrets(t, backend, `vault("secret/data/app", "username")`)
+	assert.True(t, diag.HasErrors())
+	_, diag = evalWithSecrets(t, backend, `vault("secret/data/other", "password")`)
+	assert.True(t, diag.HasErrors())
+	_, diag = evalWithSecrets(t, backend, `consul_key("other")`)
+	assert.True(t, diag.HasErrors())
+}
+
+func TestSecretBackend_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+  "vault": {"secret/data/app": {"password": "from-file"}},
+  "consul": {"app/endpoint": "https://example.com"}
+}`), 0600))
+	backend := FileSecretBackend{Path: path}
+
+	v, diag := evalWithSecrets(t, backend, `vault("secret/data/app", "password")`)
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, "from-file", v)
+
+	v, diag = evalWithSecrets(t, backend, `consul_key("app/endpoint")`)
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, "https://example.com", v)
+
+	_, diag = evalWithSecrets(t, FileSecretBackend{Path: filepath.Join(t.TempDir(), "missing.json")}, `consul_key("app/endpoint")`)
+	assert.True(t, diag.HasErrors())
+}
+
+func TestWithEphemeralSecrets(t *testing.T) {
+	backend := WithSecretBackend(MemorySecretBackend{
+		VaultSecrets: map[string]map[string]string{"secret/data/app": {"password": "s3cr3t"}},
+		ConsulKeys:   map[string]string{"app/endpoint": "https://example.com"},
+	})
+	for _, code := range []string{`vault("secret/data/app", "password")`, `consul_key("app/endpoint")`} {
+		v, diag := evalFS(t, code, nil, backend, WithEphemeralSecrets(true))
+		require.False(t, diag.HasErrors(), diag.Error())
+		assert.True(t, v.HasMark(marks.Ephemeral), code)
+
+		v, diag = evalFS(t, code, nil, backend)
+		require.False(t, diag.HasErrors(), diag.Error())
+		assert.False(t, v.HasMark(marks.Ephemeral), code)
+	}
+}
+
+func TestWithSensitiveSecrets(t *testing.T) {
+	backend := WithSecretBackend(MemorySecretBackend{
+		VaultSecrets: map[string]map[string]string{"secret/data/app": {"password": "s3cr3t"}},
+		ConsulKeys:   map[string]string{"app/endpoint": "https://example.com"},
+	})
+	for _, code := range []string{`vault("secret/data/app", "password")`, `consul_key("app/endpoint")`} {
+		v, diag := evalFS(t, code, nil, backend)
+		require.False(t, diag.HasErrors(), diag.Error())
+		assert.True(t, v.HasMark(marks.Sensitive), code)
+
+		v, diag = evalFS(t, code, nil, backend, WithSensitiveSecrets(false))
+		require.False(t, diag.HasErrors(), diag.Error())
+		assert.False(t, v.HasMark(marks.Sensitive), code)
+	}
+}
@@ -8,6 +8,7 @@ import (
 )
 
 var SemverCheck = function.New(&function.Spec{
+	Description: "Checks whether a semantic version satisfies a version constraint.",
 	Params: []function.Parameter{
 		{
 			Name: "constraint",
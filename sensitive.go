@@ -10,6 +10,7 @@ import (
 // SensitiveFunc returns a value identical to its argument except that
 // OpenTofu will consider it to be sensitive.
 var SensitiveFunc = function.New(&function.Spec{
+	Description: "Takes any value and returns a copy of it marked so that it will be treated as sensitive.",
 	Params: []function.Parameter{
 		{
 			Name:             "value",
@@ -34,6 +35,7 @@ var SensitiveFunc = function.New(&function.Spec{
 // NonsensitiveFunc takes a sensitive value and returns the same value without
 // the sensitive marking, effectively exposing the value.
 var NonsensitiveFunc = function.New(&function.Spec{
+	Description: "Takes a sensitive value and returns a copy of that value with the sensitive marking removed, thereby exposing the sensitive value.",
 	Params: []function.Parameter{
 		{
 			Name:             "value",
@@ -58,6 +60,7 @@ var NonsensitiveFunc = function.New(&function.Spec{
 
 // IsSensitiveFunc returns whether or not the value is sensitive.
 var IsSensitiveFunc = function.New(&function.Spec{
+	Description: "Returns `true` if the given value is marked as sensitive.",
 	Params: []function.Parameter{
 		{
 			Name:             "value",
@@ -0,0 +1,43 @@
+package hclfuncs
+
+import (
+	"github.com/lonegunmanb/hclfuncs/marks"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// SensitivePathsFunc returns the paths of the sensitive values within its
+// argument, rendered by marks.FormatPath. The path of the argument itself
+// is an empty string.
+var SensitivePathsFunc = function.New(&function.Spec{
+	Description: "Returns the paths of the values marked as sensitive within the given value, such as `tags[\"team\"]` or `ports[0].number`. The value itself is reported as an empty path. The result is unknown until the value is wholly known.",
+	Params: []function.Parameter{
+		{
+			Name:             "value",
+			Type:             cty.DynamicPseudoType,
+			AllowUnknown:     true,
+			AllowNull:        true,
+			AllowMarked:      true,
+			AllowDynamicType: true,
+		},
+	},
+	Type: function.StaticReturnType(cty.List(cty.String)),
+	RefineResult: func(builder *cty.RefinementBuilder) *cty.RefinementBuilder {
+		return builder.NotNull()
+	},
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		// Unknown values may turn out to hold sensitive values, so nothing
+		// can be said yet.
+		if !args[0].IsWhollyKnown() {
+			return cty.UnknownVal(retType), nil
+		}
+		var paths []cty.Value
+		for _, path := range marks.Paths(args[0], marks.Sensitive) {
+			paths = append(paths, cty.StringVal(marks.FormatPath(args[0], path)))
+		}
+		if len(paths) == 0 {
+			return cty.ListValEmpty(cty.String), nil
+		}
+		return cty.ListVal(paths), nil
+	},
+})
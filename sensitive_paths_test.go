@@ -0,0 +1,36 @@
+package hclfuncs
+
+import (
+	"testing"
+
+	"github.com/lonegunmanb/hclfuncs/marks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestSensitivePaths(t *testing.T) {
+	cases := map[string]cty.Value{
+		`sensitivepaths({ a = "x", b = { c = [1, sensitive(2)] } })`: cty.ListVal([]cty.Value{cty.StringVal("b.c[1]")}),
+		`sensitivepaths(sensitive("x"))`:                             cty.ListVal([]cty.Value{cty.StringVal("")}),
+		`sensitivepaths({ a = "x" })`:                                cty.ListValEmpty(cty.String),
+	}
+	for code, expected := range cases {
+		t.Run(code, func(t *testing.T) {
+			v, diag := evalFS(t, code, nil)
+			require.False(t, diag.HasErrors(), diag.Error())
+			assert.True(t, expected.RawEquals(v), v.GoString())
+		})
+	}
+
+	for _, arg := range []cty.Value{
+		cty.UnknownVal(cty.String).Mark(marks.Sensitive),
+		cty.DynamicVal,
+		cty.ObjectVal(map[string]cty.Value{"a": cty.StringVal("x").Mark(marks.Sensitive), "b": cty.UnknownVal(cty.String)}),
+	} {
+		v, err := SensitivePathsFunc.Call([]cty.Value{arg})
+		require.NoError(t, err)
+		assert.False(t, v.IsKnown(), v.GoString())
+		assert.True(t, v.Type().Equals(cty.List(cty.String)))
+	}
+}
@@ -0,0 +1,130 @@
+package hclfuncs
+
+import (
+	"crypto/dsa"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"strings"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHPubKeyDecodeFunc constructs a function that decodes an SSH public key in
+// the authorized_keys format.
+var SSHPubKeyDecodeFunc = function.New(&function.Spec{
+	Description: "Decodes an SSH public key in the `authorized_keys` format. Returns its `type`, its `comment`, its length in `bits`, and its `fingerprint_sha256` and `fingerprint_md5`, in the formats printed by `ssh-keygen -l`. For certificates, the bits and fingerprints are those of the certified key.",
+	Params: []function.Parameter{
+		{
+			Name: "str",
+			Type: cty.String,
+		},
+	},
+	Type: function.StaticReturnType(cty.Object(map[string]cty.Type{
+		"type":               cty.String,
+		"comment":            cty.String,
+		"bits":               cty.Number,
+		"fingerprint_sha256": cty.String,
+		"fingerprint_md5":    cty.String,
+	})),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		key, err := sshPubKeyArg(args[0], 0)
+		if err != nil {
+			return cty.UnknownVal(retType), err
+		}
+		return cty.ObjectVal(map[string]cty.Value{
+			"type":               cty.StringVal(key.typ),
+			"comment":            cty.StringVal(key.comment),
+			"bits":               cty.NumberIntVal(int64(key.bits)),
+			"fingerprint_sha256": cty.StringVal(ssh.FingerprintSHA256(key.key)),
+			"fingerprint_md5":    cty.StringVal("MD5:" + ssh.FingerprintLegacyMD5(key.key)),
+		}), nil
+	},
+})
+
+// SSHFingerprintFunc constructs a function that computes the SHA256
+// fingerprint of an SSH public key.
+var SSHFingerprintFunc = function.New(&function.Spec{
+	Description: "Returns the SHA256 fingerprint of an SSH public key or certificate in the `authorized_keys` format, such as `SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8`.",
+	Params: []function.Parameter{
+		{
+			Name: "str",
+			Type: cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(cty.String),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		key, err := sshPubKeyArg(args[0], 0)
+		if err != nil {
+			return cty.UnknownVal(cty.String), err
+		}
+		return cty.StringVal(ssh.FingerprintSHA256(key.key)), nil
+	},
+})
+
+// sshPublicKey is a parsed SSH public key. For certificates, key is the
+// certified key, which is the one ssh-keygen fingerprints.
+type sshPublicKey struct {
+	typ     string
+	comment string
+	key     ssh.PublicKey
+	bits    int
+}
+
+// sshPubKeyArg parses the single SSH public key of v, with its comment. It
+// parses the first line that isn't blank or a comment on its own, since
+// ssh.ParseAuthorizedKey skips the lines it can't parse.
+func sshPubKeyArg(v cty.Value, argIdx int) (sshPublicKey, error) {
+	lines := strings.Split(v.AsString(), "\n")
+	i := 0
+	for i < len(lines)-1 {
+		line := strings.TrimSpace(lines[i])
+		if line != "" && !strings.HasPrefix(line, "#") {
+			break
+		}
+		i++
+	}
+	key, comment, options, _, err := ssh.ParseAuthorizedKey([]byte(lines[i]))
+	if err != nil {
+		return sshPublicKey{}, function.NewArgErrorf(argIdx, "invalid SSH public key: %s", err)
+	}
+	if len(options) > 0 {
+		return sshPublicKey{}, function.NewArgErrorf(argIdx, "invalid SSH public key: unexpected options")
+	}
+	if strings.TrimSpace(strings.Join(lines[i+1:], "\n")) != "" {
+		return sshPublicKey{}, function.NewArgErrorf(argIdx, "invalid SSH public key: expected a single key")
+	}
+	r := sshPublicKey{typ: key.Type(), comment: comment, key: key}
+	if cert, ok := key.(*ssh.Certificate); ok {
+		r.key = cert.Key
+	}
+	bits, ok := sshKeyBits(r.key)
+	if !ok {
+		return sshPublicKey{}, function.NewArgErrorf(argIdx, "invalid SSH public key: unsupported key type %s", key.Type())
+	}
+	r.bits = bits
+	return r, nil
+}
+
+// sshKeyBits returns the length of key in bits, as reported by ssh-keygen.
+func sshKeyBits(key ssh.PublicKey) (int, bool) {
+	ck, ok := key.(ssh.CryptoPublicKey)
+	if !ok {
+		return 0, false
+	}
+	switch k := ck.CryptoPublicKey().(type) {
+	case *rsa.PublicKey:
+		return k.N.BitLen(), true
+	case *dsa.PublicKey:
+		return k.P.BitLen(), true
+	case *ecdsa.PublicKey:
+		return k.Curve.Params().BitSize, true
+	case ed25519.PublicKey:
+		return 256, true
+	}
+	return 0, false
+}
@@ -0,0 +1,98 @@
+package hclfuncs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// The fingerprints of these keys were computed with ssh-keygen -l.
+const (
+	testSSHPublicKey    = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAICQygsTO7Nn8Akw83xmq/MJUsHIWqMHizJy/SMHNsTuf alice@example.com"
+	testSSHRSAPublicKey = "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAABAQDAfUvXwxekRnh4mpgfbm8Cxuq3FbnznV+LZlyq3wNg4ClOScsIHV+nZ90xJ7kbAbl4MjbO0qnofe0h19cB1HKVXo72OL+W2gTpxNK87WU2IrzylP27fHCG7vmi4gDRMIfGiKW5EIE+z8Z2NiTUBm88gTS99qgHHLxp/28X9ydiD1WH/hQK2kYqWVO3Gytfl2zw+e7O4HgJBOreKCqfdSoX/CGkKHdr8WXZOU/O3/naTZVMXU3sT0igSB+uL+9Ben2qkX8UD/20Sa8GnShg1j3SPE5vEerVJnGsEGE94iKl3o1PCuPtJmxSnbWRINGmHS/c1D8SBPb8RzXRCsl1kFtt"
+	testSSHDSAPublicKey = "ssh-dss AAAAB3NzaC1kc3MAAACBAJo3p62m7A3/p5o9WcubGknt9zPCBMXzqh4GvbjUts++MlbC3dyNz0glLMj6yHTptBiTgFGUSGh91VPRB+qzP7NAY6Ow5chHbczD+p8+japkQCwYVvtdFPuGZpyQbccGZx+HpaLIc734du1BcCPwX4cJKF6BYVXfhKxz9XhqMp0HAAAAFQC5b3lXfDnHFWCbhrfbRQsObmbI2wAAAIEAiUG021Irm5BnWou/UcyD4V1Vg1WPQH1L33Hyl7Z1C3UulI2TpARX7/L7OTGAdbQ9jGbSWg5D0q8VaYOlvDME3iTyVSFvp4YeZR1cGpm4Ko9jC1UhJanvDpP3CNNyZiTsfwR/l4Cq2/+iyWWtcIGAGkhLAv9fByp7REkbAGL8UlsAAACBAIzQaVHku62x2X2mQUP8NVKwlowY2/ptWydgDmdexUEShdxS3cpeD4WR4YOCenJaRrCgQtw6n+beuzLte8yWER3PWiJFaybuvvLHY4Nr2RR/Y+Wk+59v+JoWQoyPVzaAMFVIQM2e5otkmiqtgnef33go3Iua/bF2GA8kGahorY7z dsa"
+	// testSSHCertificate certifies testSSHPublicKey.
+	testSSHCertificate = "ssh-ed25519-cert-v01@openssh.com AAAAIHNzaC1lZDI1NTE5LWNlcnQtdjAxQG9wZW5zc2guY29tAAAAIJjDo7BY3scmN8HQnk+NIH7U9FltmWpMYEE3yizUK++MAAAAICQygsTO7Nn8Akw83xmq/MJUsHIWqMHizJy/SMHNsTufAAAAAAAAAAAAAAABAAAAAmlkAAAACQAAAAVhbGljZQAAAAAAAAAA//////////8AAAAAAAAAggAAABVwZXJtaXQtWDExLWZvcndhcmRpbmcAAAAAAAAAF3Blcm1pdC1hZ2VudC1mb3J3YXJkaW5nAAAAAAAAABZwZXJtaXQtcG9ydC1mb3J3YXJkaW5nAAAAAAAAAApwZXJtaXQtcHR5AAAAAAAAAA5wZXJtaXQtdXNlci1yYwAAAAAAAAAAAAAAMwAAAAtzc2gtZWQyNTUxOQAAACADWYYB1BcGoilTDSMBaK9OcgLQUspsSPveizLDi72iWQAAAFMAAAALc3NoLWVkMjU1MTkAAABA8aLED1dzGfPDqaWKmGJS0EF8bQ+UnxgblUUmz3pznxL/rsdpmIXpe4PlkedWUOUSMEJwc2679X6lmQEduaprAw== alice@example.com"
+)
+
+func TestSSHPubKeyDecode(t *testing.T) {
+	v, err := SSHPubKeyDecodeFunc.Call([]cty.Value{cty.StringVal(testSSHPublicKey + "\n")})
+	require.NoError(t, err)
+	expected := cty.ObjectVal(map[string]cty.Value{
+		"type":               cty.StringVal("ssh-ed25519"),
+		"comment":            cty.StringVal("alice@example.com"),
+		"bits":               cty.NumberIntVal(256),
+		"fingerprint_sha256": cty.StringVal("SHA256:oBhSZL2hs2RB7Qeq3tmMAPpVzmneBVCUPxX4xixhTcs"),
+		"fingerprint_md5":    cty.StringVal("MD5:c3:c2:dc:54:c4:84:ad:01:a0:cb:f7:f9:11:f6:6c:23"),
+	})
+	assert.True(t, expected.RawEquals(v), v.GoString())
+
+	v, err = SSHPubKeyDecodeFunc.Call([]cty.Value{cty.StringVal(testSSHRSAPublicKey)})
+	require.NoError(t, err)
+	assert.Equal(t, "ssh-rsa", v.GetAttr("type").AsString())
+	assert.Equal(t, "", v.GetAttr("comment").AsString())
+	assert.True(t, cty.NumberIntVal(2048).RawEquals(v.GetAttr("bits")))
+
+	v, err = SSHPubKeyDecodeFunc.Call([]cty.Value{cty.StringVal(testSSHDSAPublicKey)})
+	require.NoError(t, err)
+	assert.Equal(t, "ssh-dss", v.GetAttr("type").AsString())
+	assert.True(t, cty.NumberIntVal(1024).RawEquals(v.GetAttr("bits")))
+	assert.Equal(t, "SHA256:q7PpYitGm6Ds0TQFnZd5nAESeh07ezJZVlgl36ufKG0", v.GetAttr("fingerprint_sha256").AsString())
+
+	v, err = SSHPubKeyDecodeFunc.Call([]cty.Value{cty.StringVal(testSSHCertificate + "\n\n  \n")})
+	require.NoError(t, err)
+	expected = cty.ObjectVal(map[string]cty.Value{
+		"type":               cty.StringVal("ssh-ed25519-cert-v01@openssh.com"),
+		"comment":            cty.StringVal("alice@example.com"),
+		"bits":               cty.NumberIntVal(256),
+		"fingerprint_sha256": cty.StringVal("SHA256:oBhSZL2hs2RB7Qeq3tmMAPpVzmneBVCUPxX4xixhTcs"),
+		"fingerprint_md5":    cty.StringVal("MD5:c3:c2:dc:54:c4:84:ad:01:a0:cb:f7:f9:11:f6:6c:23"),
+	})
+	assert.True(t, expected.RawEquals(v), v.GoString())
+}
+
+func TestSSHFingerprint(t *testing.T) {
+	v, err := SSHFingerprintFunc.Call([]cty.Value{cty.StringVal(testSSHRSAPublicKey)})
+	require.NoError(t, err)
+	assert.Equal(t, "SHA256:elCZNR/LKsikDC0P3S7qhuyYUDKCOlQ03bfZuPH15sk", v.AsString())
+
+	v, err = SSHFingerprintFunc.Call([]cty.Value{cty.StringVal("# deploy key\n\n" + testSSHRSAPublicKey)})
+	require.NoError(t, err)
+	assert.Equal(t, "SHA256:elCZNR/LKsikDC0P3S7qhuyYUDKCOlQ03bfZuPH15sk", v.AsString())
+
+	v, err = SSHFingerprintFunc.Call([]cty.Value{cty.StringVal(testSSHCertificate)})
+	require.NoError(t, err)
+	assert.Equal(t, "SHA256:oBhSZL2hs2RB7Qeq3tmMAPpVzmneBVCUPxX4xixhTcs", v.AsString())
+}
+
+func TestSSHPubKey_Invalid(t *testing.T) {
+	for name, key := range map[string]string{
+		"garbage":                  "not a key",
+		"truncated":                testSSHPublicKey[:40],
+		"options":                  `command="ls" ` + testSSHPublicKey,
+		"multiple":                 testSSHPublicKey + "\n" + testSSHRSAPublicKey,
+		"garbage before a key":     "this is garbage\n" + testSSHPublicKey,
+		"invalid key before a key": "ssh-rsa AAAAinvalid\n" + testSSHPublicKey,
+		"blank":                    " \n\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			for _, f := range []function.Function{SSHPubKeyDecodeFunc, SSHFingerprintFunc} {
+				_, err := f.Call([]cty.Value{cty.StringVal(key)})
+				var argErr function.ArgError
+				require.ErrorAs(t, err, &argErr)
+				assert.Equal(t, 0, argErr.Index)
+				assert.ErrorContains(t, err, "invalid SSH public key")
+			}
+		})
+	}
+}
+
+func TestSSHPubKey_Diagnostics(t *testing.T) {
+	_, diags := evalFS(t, `sshfingerprint("ssh-rsa AAAA")`, nil)
+	require.True(t, diags.HasErrors())
+	assert.Contains(t, diags.Error(), "Invalid function argument")
+	assert.Contains(t, diags.Error(), "invalid SSH public key")
+}
@@ -11,6 +11,7 @@ import (
 // a specific prefix using strings.HasPrefix
 // Copy from https://github.com/opentofu/opentofu/blob/v1.7.1/internal/lang/funcs/string.go
 var StartsWithFunc = function.New(&function.Spec{
+	Description: "Takes two values: a string to check and a prefix string. The function returns true if the first string begins with that exact prefix.",
 	Params: []function.Parameter{
 		{
 			Name:         "str",
@@ -62,6 +63,7 @@ var StartsWithFunc = function.New(&function.Spec{
 // a specific suffix using strings.HasSuffix
 // Copy from https://github.com/opentofu/opentofu/blob/v1.7.1/internal/lang/funcs/string.go
 var EndsWithFunc = function.New(&function.Spec{
+	Description: "Takes two values: a string to check and a suffix string. The function returns true if the first string ends with that exact suffix.",
 	Params: []function.Parameter{
 		{
 			Name: "str",
@@ -90,6 +92,7 @@ var EndsWithFunc = function.New(&function.Spec{
 // if found the function returns true, otherwise returns false.
 // Copy from https://github.com/opentofu/opentofu/blob/v1.7.1/internal/lang/funcs/string.go
 var StrContainsFunc = function.New(&function.Spec{
+	Description: "Checks whether a substring is within another string.",
 	Params: []function.Parameter{
 		{
 			Name: "str",
@@ -115,6 +118,7 @@ var StrContainsFunc = function.New(&function.Spec{
 
 // Copy from https://github.com/opentofu/opentofu/blob/v1.8.2/internal/lang/funcs/string.go#L97-L132
 var ReplaceFunc = function.New(&function.Spec{
+	Description: "Searches a given string for another given substring, and replaces each occurrence with a given replacement string.",
 	Params: []function.Parameter{
 		{
 			Name: "str",
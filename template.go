@@ -0,0 +1,283 @@
+// Adapted from https://github.com/opentofu/opentofu/blob/v1.7.1/internal/lang/funcs/filesystem.go
+package hclfuncs
+
+import (
+	"fmt"
+	"io/fs"
+	"sync"
+
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// maxTemplateDepth bounds how deeply templates may render other templates.
+const maxTemplateDepth = 16
+
+// MakeTemplateFileFunc constructs a function that renders the template file
+// at the given path, read from fsys or from the host OS when fsys is nil. The
+// template can call the functions returned by funcsCb; the callback
+// indirection lets the template functions be part of that same table.
+func MakeTemplateFileFunc(fsys fs.FS, baseDir string, funcsCb func() map[string]function.Function) function.Function {
+	f := templateRenderer{
+		files:   fileSystem{fsys: fsys, baseDir: baseDir},
+		funcsCb: funcsCb,
+		nested:  constructedTemplate,
+	}.templateFileFunc(nil)
+	constructedTemplates.Store(f, "templatefile")
+	return f
+}
+
+// MakeTemplateStringFunc constructs a function that renders the given
+// template string. The template can call the functions returned by funcsCb.
+func MakeTemplateStringFunc(funcsCb func() map[string]function.Function) function.Function {
+	f := templateRenderer{funcsCb: funcsCb, nested: constructedTemplate}.templateStringFunc(nil)
+	constructedTemplates.Store(f, "templatestring")
+	return f
+}
+
+// constructedTemplates maps the functions returned by MakeTemplateFileFunc
+// and MakeTemplateStringFunc to their names.
+var constructedTemplates sync.Map
+
+// constructedTemplate returns "templatefile" or "templatestring" when fn,
+// found in a table as name, was returned by the constructor of that
+// function, and an empty string otherwise. Like nestedTemplate for the
+// tables built by NewFunctions, it leaves other functions with those names,
+// such as overrides, for templates to call as they are.
+func constructedTemplate(name string, fn function.Function) string {
+	kind, ok := constructedTemplates.Load(fn)
+	if !ok || kind != localName(name) {
+		return ""
+	}
+	return kind.(string)
+}
+
+// templateFileFactory and templateStringFactory build the template functions
+// of a table. Both share the table's file system so that templatefile calls
+// nested in a template string resolve paths the same way the table's
+// templatefile does.
+func templateFileFactory(c *config) function.Function {
+	return templateRenderer{files: c.files(), funcsCb: c.table, nested: c.nestedTemplate}.templateFileFunc(nil)
+}
+
+func templateStringFactory(c *config) function.Function {
+	return templateRenderer{files: c.files(), funcsCb: c.table, nested: c.nestedTemplate}.templateStringFunc(nil)
+}
+
+// nestedTemplate returns "templatefile" or "templatestring" when the table
+// entry name is the table's own implementation of that function, and an
+// empty string otherwise. Stubs, overrides and user functions are left for
+// templates to call as they are.
+func (c *config) nestedTemplate(name string, _ function.Function) string {
+	builtin := c.builtins[name]
+	if builtin != "templatefile" && builtin != "templatestring" {
+		return ""
+	}
+	if _, ok := c.overrides[builtin]; ok {
+		return ""
+	}
+	if c.stubbed[builtin] {
+		return ""
+	}
+	return builtin
+}
+
+// templateRenderer renders templates. The chain given to its functions lists
+// the templates being rendered by the enclosing calls, so that a template
+// that includes itself, directly or not, is reported instead of recursing
+// forever. nested tells which functions of the table are template functions
+// that should carry the chain along.
+type templateRenderer struct {
+	files   fileSystem
+	funcsCb func() map[string]function.Function
+	nested  func(name string, fn function.Function) string
+}
+
+func (r templateRenderer) templateFileFunc(chain []string) function.Function {
+	params := []function.Parameter{
+		{
+			Name:        "path",
+			Description: "Path of the template file, relative to the base directory.",
+			Type:        cty.String,
+			AllowMarked: true,
+		},
+		{
+			Name:        "vars",
+			Description: "Object or map of the variables the template can refer to.",
+			Type:        cty.DynamicPseudoType,
+		},
+	}
+
+	loadTmpl := func(fn string) (hclsyntax.Expression, []string, error) {
+		name, err := r.files.resolve(fn)
+		if err != nil {
+			return nil, nil, err
+		}
+		inner, err := enterTemplate(chain, "file:"+name, fmt.Errorf("template file %s recursively includes itself", fn))
+		if err != nil {
+			return nil, nil, err
+		}
+		src, err := r.files.readFile(fn)
+		if err != nil {
+			return nil, nil, err
+		}
+		expr, diags := hclsyntax.ParseTemplate(src, fn, hcl.InitialPos)
+		if diags.HasErrors() {
+			return nil, nil, diags
+		}
+		return expr, inner, nil
+	}
+
+	return function.New(&function.Spec{
+		Description: "Reads the file at the given path and renders its content as a template using the supplied set of template variables.",
+		Params:      params,
+		Type: func(args []cty.Value) (cty.Type, error) {
+			if !(args[0].IsKnown() && args[1].IsKnown()) {
+				return cty.DynamicPseudoType, nil
+			}
+
+			// We'll render our template now to see what result type it
+			// produces. A template consisting only of a single interpolation
+			// can potentially return any type.
+			pathArg, _ := args[0].Unmark()
+			expr, inner, err := loadTmpl(pathArg.AsString())
+			if err != nil {
+				return cty.DynamicPseudoType, err
+			}
+
+			// This is safe even if args[1] contains unknowns because the HCL
+			// template renderer itself knows how to short-circuit those.
+			val, err := r.render(expr, args[1], inner)
+			return val.Type(), err
+		},
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			pathArg, pathMarks := args[0].Unmark()
+			expr, inner, err := loadTmpl(pathArg.AsString())
+			if err != nil {
+				return cty.DynamicVal, err
+			}
+			result, err := r.render(expr, args[1], inner)
+			return result.WithMarks(pathMarks), err
+		},
+	})
+}
+
+func (r templateRenderer) templateStringFunc(chain []string) function.Function {
+	loadTmpl := func(tpl string) (hclsyntax.Expression, []string, error) {
+		inner, err := enterTemplate(chain, "string:"+tpl, fmt.Errorf("template string recursively renders itself"))
+		if err != nil {
+			return nil, nil, err
+		}
+		expr, diags := hclsyntax.ParseTemplate([]byte(tpl), "<templatestring>", hcl.InitialPos)
+		if diags.HasErrors() {
+			return nil, nil, diags
+		}
+		return expr, inner, nil
+	}
+
+	return function.New(&function.Spec{
+		Description: "Renders the given string as a template using the supplied set of template variables.",
+		Params: []function.Parameter{
+			{
+				Name:        "template",
+				Description: "The template source.",
+				Type:        cty.String,
+			},
+			{
+				Name:        "vars",
+				Description: "Object or map of the variables the template can refer to.",
+				Type:        cty.DynamicPseudoType,
+			},
+		},
+		Type: func(args []cty.Value) (cty.Type, error) {
+			if !(args[0].IsKnown() && args[1].IsKnown()) {
+				return cty.DynamicPseudoType, nil
+			}
+			expr, inner, err := loadTmpl(args[0].AsString())
+			if err != nil {
+				return cty.DynamicPseudoType, err
+			}
+			val, err := r.render(expr, args[1], inner)
+			return val.Type(), err
+		},
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			expr, inner, err := loadTmpl(args[0].AsString())
+			if err != nil {
+				return cty.DynamicVal, err
+			}
+			return r.render(expr, args[1], inner)
+		},
+	})
+}
+
+// enterTemplate returns the chain extended with key, or an error if key is
+// already being rendered or the chain is too deep.
+func enterTemplate(chain []string, key string, recursionErr error) ([]string, error) {
+	if len(chain) >= maxTemplateDepth {
+		return nil, fmt.Errorf("templates are nested more than %d levels deep", maxTemplateDepth)
+	}
+	for _, k := range chain {
+		if k == key {
+			return nil, recursionErr
+		}
+	}
+	// Copy so that sibling calls never share the backing array.
+	return append(chain[:len(chain):len(chain)], key), nil
+}
+
+func (r templateRenderer) render(expr hclsyntax.Expression, varsVal cty.Value, chain []string) (cty.Value, error) {
+	if varsTy := varsVal.Type(); !(varsTy.IsMapType() || varsTy.IsObjectType()) {
+		return cty.DynamicVal, function.NewArgErrorf(1, "invalid vars value: must be a map") // or an object, but we don't strongly distinguish these most of the time
+	}
+
+	ctx := &hcl.EvalContext{
+		Variables: varsVal.AsValueMap(),
+	}
+
+	// We require all of the variables to be valid HCL identifiers, because
+	// otherwise there would be no way to refer to them in the template
+	// anyway. Rejecting this here gives better feedback to the user
+	// than a syntax error somewhere in the template itself.
+	for n := range ctx.Variables {
+		if !hclsyntax.ValidIdentifier(n) {
+			// This error message intentionally doesn't describe _all_ of
+			// the different permutations that are technically valid as an
+			// HCL identifier, but rather focuses on what we might
+			// consider to be an "idiomatic" variable name.
+			return cty.DynamicVal, function.NewArgErrorf(1, "invalid template variable name %q: must start with a letter, followed by zero or more letters, digits, and underscores", n)
+		}
+	}
+
+	// We'll pre-check references in the template here so we can give a
+	// more specialized error message than HCL would by default, so it's
+	// clearer that this problem is coming from a template call.
+	for _, traversal := range expr.Variables() {
+		root := traversal.RootName()
+		if _, ok := ctx.Variables[root]; !ok {
+			return cty.DynamicVal, function.NewArgErrorf(1, "vars map does not contain key %q, referenced at %s", root, traversal[0].SourceRange())
+		}
+	}
+
+	// Nested template calls, whether by flat or namespaced name, carry the
+	// chain along so that recursion can be detected.
+	givenFuncs := r.funcsCb()
+	funcs := make(map[string]function.Function, len(givenFuncs))
+	for name, fn := range givenFuncs {
+		switch r.nested(name, fn) {
+		case "templatefile":
+			fn = r.templateFileFunc(chain)
+		case "templatestring":
+			fn = r.templateStringFunc(chain)
+		}
+		funcs[name] = fn
+	}
+	ctx.Functions = funcs
+
+	val, diags := expr.Value(ctx)
+	if diags.HasErrors() {
+		return cty.DynamicVal, diags
+	}
+	return val, nil
+}
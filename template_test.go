@@ -0,0 +1,137 @@
+package hclfuncs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+var templateFS = fstest.MapFS{
+	"tpl/hello.tmpl":   {Data: []byte(`Hello, ${upper(name)}!`)},
+	"tpl/list.tmpl":    {Data: []byte("%{ for x in items }- ${x}\n%{ endfor }")},
+	"tpl/outer.tmpl":   {Data: []byte(`[${templatefile("hello.tmpl", { name = name })}]`)},
+	"tpl/self.tmpl":    {Data: []byte(`${templatefile("self.tmpl", {})}`)},
+	"tpl/ping.tmpl":    {Data: []byte(`${templatefile("pong.tmpl", {})}`)},
+	"tpl/pong.tmpl":    {Data: []byte(`${templatefile("ping.tmpl", {})}`)},
+	"tpl/broken.tmpl":  {Data: []byte("line one\n${unknownfunc()}")},
+	"tpl/missing.tmpl": {Data: []byte(`${nope}`)},
+}
+
+func TestTemplateFile(t *testing.T) {
+	cases := map[string]string{
+		`templatefile("hello.tmpl", { name = "world" })`:     "Hello, WORLD!",
+		`templatefile("list.tmpl", { items = ["a", "b"] })`:  "- a\n- b\n",
+		`templatefile("outer.tmpl", { name = "nested" })`:    "[Hello, NESTED!]",
+		`templatefile("/tpl/hello.tmpl", { name = "root" })`: "Hello, ROOT!",
+	}
+	for code, expected := range cases {
+		t.Run(code, func(t *testing.T) {
+			v, diag := evalFS(t, code, nil, WithFS(templateFS), WithBaseDir("tpl"))
+			require.False(t, diag.HasErrors(), diag.Error())
+			assert.Equal(t, expected, v.AsString())
+		})
+	}
+}
+
+func TestTemplateFile_Errors(t *testing.T) {
+	cases := map[string]string{
+		`templatefile("self.tmpl", {})`:             "template file self.tmpl recursively includes itself",
+		`templatefile("ping.tmpl", {})`:             "template file ping.tmpl recursively includes itself",
+		`templatefile("broken.tmpl", {})`:           "broken.tmpl:2,3-14",
+		`templatefile("missing.tmpl", {})`:          `vars map does not contain key "nope"`,
+		`templatefile("hello.tmpl", { "1st" = 1 })`: `invalid template variable name "1st"`,
+		`templatefile("none.tmpl", {})`:             "no file exists at tpl/none.tmpl",
+	}
+	for code, expected := range cases {
+		t.Run(code, func(t *testing.T) {
+			_, diag := evalFS(t, code, nil, WithFS(templateFS), WithBaseDir("tpl"))
+			assert.ErrorContains(t, diag, expected)
+		})
+	}
+}
+
+func TestTemplateFile_OS(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.tmpl"), []byte(`${a + 1}`), 0600))
+	v, err := Functions(dir)["templatefile"].Call([]cty.Value{
+		cty.StringVal("a.tmpl"),
+		cty.ObjectVal(map[string]cty.Value{"a": cty.NumberIntVal(1)}),
+	})
+	require.NoError(t, err)
+	assert.True(t, v.RawEquals(cty.NumberIntVal(2)))
+}
+
+func TestTemplateString(t *testing.T) {
+	v, diag := evalFS(t, `templatestring("%%{ for x in xs }$${upper(x)}%%{ endfor }", { xs = ["a", "b"] })`, nil)
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, "AB", v.AsString())
+
+	v, diag = evalFS(t, `templatestring("$${templatestring(inner, {})}", { inner = "x" })`, nil)
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, "x", v.AsString())
+
+	v, diag = evalFS(t, `templatestring("$${templatefile(\"hello.tmpl\", { name = \"fs\" })}", {})`, nil, WithFS(templateFS), WithBaseDir("tpl"))
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, "Hello, FS!", v.AsString())
+
+	code := `templatestring("$${templatestring(b, { a = a, b = b })}", { a = "$${templatestring(b, { a = a, b = b })}", b = "$${templatestring(a, { a = a, b = b })}" })`
+	_, diag = evalFS(t, code, nil)
+	assert.ErrorContains(t, diag, "template string recursively renders itself")
+
+	code = `templatestring("$${templatestring(tpl, { tpl = tpl })}", { tpl = "$${templatestring(tpl, { tpl = tpl })}" })`
+	_, diag = evalFS(t, code, nil)
+	assert.ErrorContains(t, diag, "template string recursively renders itself")
+}
+
+func TestTemplateString_NestedUsesTable(t *testing.T) {
+	code := `templatestring("$${templatefile(\"hello.tmpl\", { name = \"x\" })}", {})`
+
+	v, diag := evalFS(t, code, nil, WithFS(templateFS), WithBaseDir("tpl"), WithImpureMode(ImpureStub))
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.False(t, v.IsKnown())
+
+	custom := function.New(&function.Spec{
+		Params: []function.Parameter{{Name: "path", Type: cty.String}, {Name: "vars", Type: cty.DynamicPseudoType}},
+		Type:   function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			return cty.StringVal("CUSTOM"), nil
+		},
+	})
+	v, diag = evalFS(t, code, nil, WithFS(templateFS), WithBaseDir("tpl"), WithOverride("templatefile", custom))
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, "CUSTOM", v.AsString())
+
+	v, diag = evalFS(t, `templatestring("$${ns::templatefile(\"a\", {})}", {})`, nil, WithFunction("ns::templatefile", custom))
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, "CUSTOM", v.AsString())
+}
+
+func TestMakeTemplateFuncs(t *testing.T) {
+	fsys := fstest.MapFS{
+		"loop.tmpl": {Data: []byte(`${templatestring("$${templatefile(\"loop.tmpl\", {})}", {})}`)},
+	}
+	funcs := map[string]function.Function{}
+	cb := func() map[string]function.Function { return funcs }
+	funcs["templatefile"] = MakeTemplateFileFunc(fsys, ".", cb)
+	funcs["templatestring"] = MakeTemplateStringFunc(cb)
+
+	_, err := funcs["templatefile"].Call([]cty.Value{cty.StringVal("loop.tmpl"), cty.EmptyObjectVal})
+	assert.ErrorContains(t, err, "template file loop.tmpl recursively includes itself")
+
+	funcs["templatefile"] = function.New(&function.Spec{
+		Params: []function.Parameter{{Name: "path", Type: cty.String}, {Name: "vars", Type: cty.DynamicPseudoType}},
+		Type:   function.StaticReturnType(cty.String),
+		Impl: func(args []cty.Value, retType cty.Type) (cty.Value, error) {
+			return cty.StringVal("CUSTOM"), nil
+		},
+	})
+	v, err := funcs["templatestring"].Call([]cty.Value{cty.StringVal(`${templatefile("loop.tmpl", {})}`), cty.EmptyObjectVal})
+	require.NoError(t, err)
+	assert.Equal(t, "CUSTOM", v.AsString())
+}
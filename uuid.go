@@ -0,0 +1,160 @@
+package hclfuncs
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+// UUIDv7Func constructs a function that generates a time-ordered version 7
+// UUID from the current system time.
+var UUIDv7Func = MakeUUIDv7Func(SystemClock)
+
+// MakeUUIDv7Func constructs a uuidv7 function that reads the current time
+// from the given clock. The other 74 bits of the UUID are random, so UUIDs
+// generated within the same millisecond, or from a fixed clock, differ but
+// are not ordered.
+func MakeUUIDv7Func(clock Clock) function.Function {
+	return function.New(&function.Spec{
+		Description:  "Generates a time-ordered version 7 UUID.",
+		Params:       []function.Parameter{},
+		Type:         function.StaticReturnType(cty.String),
+		RefineResult: refineNotNull,
+		Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+			var id uuid.UUID
+			if err := putTimeAndRandom(id[:], clock.Now()); err != nil {
+				return cty.UnknownVal(cty.String), err
+			}
+			id[6] = id[6]&0x0f | 0x70 // version 7
+			id[8] = id[8]&0x3f | 0x80 // RFC 9562 variant
+			return cty.StringVal(id.String()), nil
+		},
+	})
+}
+
+// ULIDFunc constructs a function that generates a ULID from the current
+// system time.
+var ULIDFunc = MakeULIDFunc(SystemClock)
+
+// MakeULIDFunc constructs a ulid function that reads the current time from
+// the given clock. As with uuidv7, the 80 bits after the timestamp are
+// random.
+func MakeULIDFunc(clock Clock) function.Function {
+	return function.New(&function.Spec{
+		Description:  "Generates a ULID, a lexicographically sortable identifier made of a timestamp in milliseconds and random bits, encoded in Crockford's Base32.",
+		Params:       []function.Parameter{},
+		Type:         function.StaticReturnType(cty.String),
+		RefineResult: refineNotNull,
+		Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+			var id [16]byte
+			if err := putTimeAndRandom(id[:], clock.Now()); err != nil {
+				return cty.UnknownVal(cty.String), err
+			}
+			return cty.StringVal(encodeULID(id)), nil
+		},
+	})
+}
+
+// putTimeAndRandom writes the Unix time of t in milliseconds to the first 48
+// bits of id, and random bits to the rest.
+func putTimeAndRandom(id []byte, t time.Time) error {
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(t.UnixMilli()))
+	copy(id[:6], ms[2:])
+	_, err := rand.Read(id[6:])
+	return err
+}
+
+const crockfordBase32 = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// encodeULID encodes the 128 bits of id as 26 characters of Crockford's
+// Base32, the first one holding the 3 most significant bits.
+func encodeULID(id [16]byte) string {
+	hi := binary.BigEndian.Uint64(id[:8])
+	lo := binary.BigEndian.Uint64(id[8:])
+	var b [26]byte
+	for i := len(b) - 1; i >= 0; i-- {
+		b[i] = crockfordBase32[lo&0x1f]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+	return string(b[:])
+}
+
+// UUIDParseFunc constructs a function that parses a UUID and describes it.
+var UUIDParseFunc = function.New(&function.Spec{
+	Description: "Parses a UUID and returns an object with its `version`, its `variant` and, for time-based versions 1, 6 and 7, the `timestamp` it was generated at, in RFC 3339 format.",
+	Params: []function.Parameter{
+		{
+			Name: "str",
+			Type: cty.String,
+		},
+	},
+	Type: function.StaticReturnType(cty.Object(map[string]cty.Type{
+		"version":   cty.Number,
+		"variant":   cty.String,
+		"timestamp": cty.String,
+	})),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		id, err := uuid.Parse(args[0].AsString())
+		if err != nil {
+			return cty.UnknownVal(retType), function.NewArgErrorf(0, "invalid UUID: %s", err)
+		}
+		timestamp := cty.NullVal(cty.String)
+		if t, ok := uuidTime(id); ok {
+			timestamp = cty.StringVal(t.UTC().Format(time.RFC3339Nano))
+		}
+		return cty.ObjectVal(map[string]cty.Value{
+			"version":   cty.NumberIntVal(int64(id.Version())),
+			"variant":   cty.StringVal(strings.ToLower(id.Variant().String())),
+			"timestamp": timestamp,
+		}), nil
+	},
+})
+
+// uuidTime returns the time that a time-based UUID was generated at.
+func uuidTime(id uuid.UUID) (time.Time, bool) {
+	if id.Variant() != uuid.RFC4122 {
+		return time.Time{}, false
+	}
+	switch id.Version() {
+	case 1:
+		sec, nsec := id.Time().UnixTime()
+		return time.Unix(sec, nsec), true
+	case 6:
+		// Unlike version 1, version 6 stores the most significant bits of
+		// the timestamp first.
+		ts := uint64(binary.BigEndian.Uint32(id[0:4]))<<28 |
+			uint64(binary.BigEndian.Uint16(id[4:6]))<<12 |
+			uint64(binary.BigEndian.Uint16(id[6:8])&0x0fff)
+		sec, nsec := uuid.Time(ts).UnixTime()
+		return time.Unix(sec, nsec), true
+	case 7:
+		ms := binary.BigEndian.Uint64(id[0:8]) >> 16
+		return time.UnixMilli(int64(ms)), true
+	}
+	return time.Time{}, false
+}
+
+// UUIDValidateFunc constructs a function that tells whether a string is a
+// valid UUID.
+var UUIDValidateFunc = function.New(&function.Spec{
+	Description: "Returns `true` if the given string is a valid UUID, in any of the forms accepted by `uuidparse`.",
+	Params: []function.Parameter{
+		{
+			Name: "str",
+			Type: cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(cty.Bool),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		return cty.BoolVal(uuid.Validate(args[0].AsString()) == nil), nil
+	},
+})
@@ -0,0 +1,80 @@
+package hclfuncs
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zclconf/go-cty/cty"
+)
+
+func TestUUIDv7AndULID(t *testing.T) {
+	clock := WithClock(FixedClock(time.Date(2022, 2, 22, 19, 22, 22, 0, time.UTC)))
+
+	v, diag := evalFS(t, `uuidv7()`, nil, clock)
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Regexp(t, `^017f22e2-79b0-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`, v.AsString())
+	other, diag := evalFS(t, `uuidv7()`, nil, clock)
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.NotEqual(t, v.AsString(), other.AsString())
+
+	v, diag = evalFS(t, `uuidparse(uuidv7()).timestamp`, nil, clock)
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Equal(t, "2022-02-22T19:22:22Z", v.AsString())
+
+	// 1645557742000 ms is 01FWHE4YDG in Crockford's Base32.
+	v, diag = evalFS(t, `ulid()`, nil, clock)
+	require.False(t, diag.HasErrors(), diag.Error())
+	assert.Regexp(t, regexp.MustCompile(`^01FWHE4YDG[0-9A-HJKMNP-TV-Z]{16}$`), v.AsString())
+}
+
+func TestUUIDParse(t *testing.T) {
+	// Examples from RFC 9562, all generated at 2022-02-22T19:22:22Z.
+	cases := map[string]cty.Value{
+		"c232ab00-9414-11ec-b3c8-9f6bdeced846": cty.ObjectVal(map[string]cty.Value{
+			"version":   cty.NumberIntVal(1),
+			"variant":   cty.StringVal("rfc4122"),
+			"timestamp": cty.StringVal("2022-02-22T19:22:22Z"),
+		}),
+		"1EC9414C-232A-6B00-B3C8-9F6BDECED846": cty.ObjectVal(map[string]cty.Value{
+			"version":   cty.NumberIntVal(6),
+			"variant":   cty.StringVal("rfc4122"),
+			"timestamp": cty.StringVal("2022-02-22T19:22:22Z"),
+		}),
+		"017F22E2-79B0-7CC3-98C4-DC0C0C07398F": cty.ObjectVal(map[string]cty.Value{
+			"version":   cty.NumberIntVal(7),
+			"variant":   cty.StringVal("rfc4122"),
+			"timestamp": cty.StringVal("2022-02-22T19:22:22Z"),
+		}),
+		"919108f7-52d1-4320-9bac-f847db4148a8": cty.ObjectVal(map[string]cty.Value{
+			"version":   cty.NumberIntVal(4),
+			"variant":   cty.StringVal("rfc4122"),
+			"timestamp": cty.NullVal(cty.String),
+		}),
+	}
+	for id, expected := range cases {
+		t.Run(id, func(t *testing.T) {
+			v, err := UUIDParseFunc.Call([]cty.Value{cty.StringVal(id)})
+			require.NoError(t, err)
+			assert.True(t, expected.RawEquals(v), v.GoString())
+		})
+	}
+
+	_, err := UUIDParseFunc.Call([]cty.Value{cty.StringVal("not-a-uuid")})
+	assert.ErrorContains(t, err, "invalid UUID")
+}
+
+func TestUUIDValidate(t *testing.T) {
+	for id, expected := range map[string]bool{
+		"919108f7-52d1-4320-9bac-f847db4148a8":          true,
+		"urn:uuid:919108f7-52d1-4320-9bac-f847db4148a8": true,
+		"919108f7-52d1-4320-9bac":                       false,
+		"":                                              false,
+	} {
+		v, err := UUIDValidateFunc.Call([]cty.Value{cty.StringVal(id)})
+		require.NoError(t, err)
+		assert.Equal(t, expected, v.True(), id)
+	}
+}
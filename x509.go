@@ -0,0 +1,151 @@
+package hclfuncs
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"time"
+
+	"github.com/zclconf/go-cty/cty"
+	"github.com/zclconf/go-cty/cty/function"
+)
+
+var pemBlockType = cty.Object(map[string]cty.Type{
+	"type":         cty.String,
+	"headers":      cty.Map(cty.String),
+	"bytes_base64": cty.String,
+})
+
+// PEMDecodeFunc constructs a function that decodes the PEM blocks of a
+// string.
+var PEMDecodeFunc = function.New(&function.Spec{
+	Description: "Decodes the PEM blocks of a string into a list of objects with the `type`, the `headers` and the Base64 encoded bytes, `bytes_base64`, of each block.",
+	Params: []function.Parameter{
+		{
+			Name: "str",
+			Type: cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(cty.List(pemBlockType)),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		var blocks []cty.Value
+		rest := []byte(args[0].AsString())
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				break
+			}
+			headers := cty.MapValEmpty(cty.String)
+			if len(block.Headers) > 0 {
+				vals := make(map[string]cty.Value, len(block.Headers))
+				for k, v := range block.Headers {
+					vals[k] = cty.StringVal(v)
+				}
+				headers = cty.MapVal(vals)
+			}
+			blocks = append(blocks, cty.ObjectVal(map[string]cty.Value{
+				"type":         cty.StringVal(block.Type),
+				"headers":      headers,
+				"bytes_base64": cty.StringVal(base64.StdEncoding.EncodeToString(block.Bytes)),
+			}))
+		}
+		if len(blocks) == 0 {
+			return cty.UnknownVal(retType), function.NewArgErrorf(0, "no PEM blocks found")
+		}
+		return cty.ListVal(blocks), nil
+	},
+})
+
+var x509CertificateType = cty.Object(map[string]cty.Type{
+	"subject":             cty.String,
+	"issuer":              cty.String,
+	"serial":              cty.String,
+	"not_before":          cty.String,
+	"not_after":           cty.String,
+	"dns_names":           cty.List(cty.String),
+	"ip_addresses":        cty.List(cty.String),
+	"email_addresses":     cty.List(cty.String),
+	"uris":                cty.List(cty.String),
+	"is_ca":               cty.Bool,
+	"key_algorithm":       cty.String,
+	"signature_algorithm": cty.String,
+	"fingerprint_sha1":    cty.String,
+	"fingerprint_sha256":  cty.String,
+})
+
+// X509DecodeFunc constructs a function that decodes the first certificate
+// of a PEM encoded string.
+var X509DecodeFunc = function.New(&function.Spec{
+	Description: "Decodes the first certificate of a PEM encoded string. Returns its `subject` and `issuer`, its `serial` number in hexadecimal, its validity period `not_before` and `not_after` as RFC 3339 timestamps, its subject alternative names `dns_names`, `ip_addresses`, `email_addresses` and `uris`, `is_ca`, its `key_algorithm` and `signature_algorithm`, and the hexadecimal SHA1 and SHA256 fingerprints `fingerprint_sha1` and `fingerprint_sha256`.",
+	Params: []function.Parameter{
+		{
+			Name: "pem",
+			Type: cty.String,
+		},
+	},
+	Type:         function.StaticReturnType(x509CertificateType),
+	RefineResult: refineNotNull,
+	Impl: func(args []cty.Value, retType cty.Type) (ret cty.Value, err error) {
+		rest := []byte(args[0].AsString())
+		for {
+			var block *pem.Block
+			block, rest = pem.Decode(rest)
+			if block == nil {
+				return cty.UnknownVal(retType), function.NewArgErrorf(0, "no CERTIFICATE PEM block found")
+			}
+			if block.Type != "CERTIFICATE" {
+				continue
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return cty.UnknownVal(retType), function.NewArgErrorf(0, "invalid certificate: %s", err)
+			}
+			return certificateVal(cert), nil
+		}
+	},
+})
+
+func certificateVal(cert *x509.Certificate) cty.Value {
+	var ips []string
+	for _, ip := range cert.IPAddresses {
+		ips = append(ips, ip.String())
+	}
+	var uris []string
+	for _, uri := range cert.URIs {
+		uris = append(uris, uri.String())
+	}
+	sha1Sum := sha1.Sum(cert.Raw)
+	sha256Sum := sha256.Sum256(cert.Raw)
+	return cty.ObjectVal(map[string]cty.Value{
+		"subject":             cty.StringVal(cert.Subject.String()),
+		"issuer":              cty.StringVal(cert.Issuer.String()),
+		"serial":              cty.StringVal(cert.SerialNumber.Text(16)),
+		"not_before":          cty.StringVal(cert.NotBefore.UTC().Format(time.RFC3339)),
+		"not_after":           cty.StringVal(cert.NotAfter.UTC().Format(time.RFC3339)),
+		"dns_names":           stringListVal(cert.DNSNames),
+		"ip_addresses":        stringListVal(ips),
+		"email_addresses":     stringListVal(cert.EmailAddresses),
+		"uris":                stringListVal(uris),
+		"is_ca":               cty.BoolVal(cert.IsCA),
+		"key_algorithm":       cty.StringVal(cert.PublicKeyAlgorithm.String()),
+		"signature_algorithm": cty.StringVal(cert.SignatureAlgorithm.String()),
+		"fingerprint_sha1":    cty.StringVal(hex.EncodeToString(sha1Sum[:])),
+		"fingerprint_sha256":  cty.StringVal(hex.EncodeToString(sha256Sum[:])),
+	})
+}
+
+func stringListVal(strs []string) cty.Value {
+	if len(strs) == 0 {
+		return cty.ListValEmpty(cty.String)
+	}
+	vals := make([]cty.Value, len(strs))
+	for i, s := range strs {
+		vals[i] = cty.StringVal(s)
+	}
+	return cty.ListVal(vals)
+}